@@ -2,8 +2,11 @@ package changelog
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"net/http"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -12,24 +15,49 @@ import (
 )
 
 const (
-	dateFormat     = "2006-01-02"
-	versionPattern = `## (?:\[)?(?:v)?([\d.]+(?:-[a-zA-Z0-9]+(?:\.[0-9]+)?)?)\]?(?:\((.*?)\))? \((\d{4}-\d{2}-\d{2})\)`
+	versionPattern = `^(#{1,6}) (?:\[)?(?:((?:[a-uw-zA-Z@]|v\D)[\w.@/-]*?)(?:-|: |@))?(?:v)?(\d+(?:\.\d+)*(?:-[a-zA-Z0-9]+(?:\.[0-9]+)?)?)\]?(?:\((.*?)\))?(?:\s+(.*))?$`
+	yankedPattern  = `(?i)\s*\[(?:yanked|retracted)(?::\s*([^\]]*))?\]`
 	changePattern  = `\* (?:\*\*(.*?)\*\*: )?(.+?)\s*(?:\((.*?)\))?(?:,\s*closes.*)?$`
 )
 
+var yankedRegex = regexp.MustCompile(yankedPattern)
+
+// errNotRelease marks a version heading that isn't a release, e.g.
+// "## 1.0 Upgrade notes".
+var errNotRelease = errors.New("not a release heading")
+
+// defaultDateLayouts are the release date formats recognised in version headings.
+var defaultDateLayouts = []string{
+	"2006-01-02",
+	"2006/01/02",
+	time.RFC3339,
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+}
+
 type ChangelogEntry struct {
-	Version    string              `json:"version"    yaml:"version"    toml:"version"`
-	Date       time.Time           `json:"date"       yaml:"date"       toml:"date"`
-	CompareURL string              `json:"compareUrl" yaml:"compareUrl" toml:"compareUrl"`
-	Changes    map[string][]Change `json:"changes"    yaml:"changes"    toml:"changes"`
+	Component    string              `json:"component,omitempty"    yaml:"component,omitempty"    toml:"component,omitempty"`
+	Version      string              `json:"version"                yaml:"version"                toml:"version"`
+	Date         time.Time           `json:"date"                   yaml:"date"                   toml:"date"`
+	Undated      bool                `json:"undated,omitempty"      yaml:"undated,omitempty"      toml:"undated,omitempty"`
+	Yanked       bool                `json:"yanked,omitempty"       yaml:"yanked,omitempty"       toml:"yanked,omitempty"`
+	YankedReason string              `json:"yankedReason,omitempty" yaml:"yankedReason,omitempty" toml:"yankedReason,omitempty"`
+	CompareURL   string              `json:"compareUrl"             yaml:"compareUrl"             toml:"compareUrl"`
+	Contributors []Contributor       `json:"contributors,omitempty" yaml:"contributors,omitempty" toml:"contributors,omitempty"`
+	Changes      map[string][]Change `json:"changes"                yaml:"changes"                toml:"changes"`
 }
 
 type Change struct {
-	Scope        string          `json:"scope,omitempty"        yaml:"scope,omitempty"        toml:"scope,omitempty"`
-	Description  string          `json:"description"            yaml:"description"            toml:"description"`
-	Commit       string          `json:"commit,omitempty"       yaml:"commit,omitempty"       toml:"commit,omitempty"`
-	CommitBody   string          `json:"commitBody,omitempty"   yaml:"commitBody,omitempty"   toml:"commitBody,omitempty"`
-	RelatedItems []*origin.Issue `json:"relatedItems,omitempty" yaml:"relatedItems,omitempty" toml:"relatedItems,omitempty"`
+	Scope         string          `json:"scope,omitempty"         yaml:"scope,omitempty"         toml:"scope,omitempty"`
+	Description   string          `json:"description"             yaml:"description"             toml:"description"`
+	Commit        string          `json:"commit,omitempty"        yaml:"commit,omitempty"        toml:"commit,omitempty"`
+	CommitBody    string          `json:"commitBody,omitempty"    yaml:"commitBody,omitempty"    toml:"commitBody,omitempty"`
+	CommitDetails *git.Commit     `json:"commitDetails,omitempty" yaml:"commitDetails,omitempty" toml:"commitDetails,omitempty"`
+	Trailers      []git.Trailer   `json:"trailers,omitempty"      yaml:"trailers,omitempty"      toml:"trailers,omitempty"`
+	Contributors  []git.Signature `json:"contributors,omitempty"  yaml:"contributors,omitempty"  toml:"contributors,omitempty"`
+	RelatedItems  []*origin.Issue `json:"relatedItems,omitempty"  yaml:"relatedItems,omitempty"  toml:"relatedItems,omitempty"`
 }
 
 type Parser struct {
@@ -38,6 +66,37 @@ type Parser struct {
 	OriginToken      string
 	IncludeBody      bool
 	FetchItemDetails bool
+	// IncludeCommitDetails attaches author, committer and signature metadata
+	// for each change's commit.
+	IncludeCommitDetails bool
+	// Repo is the repository commits and the origin URL are read from. When nil,
+	// the repository containing the working directory is opened on demand.
+	Repo *git.Repository
+	// HeadingLevel is the markdown heading level used for releases. When zero,
+	// both "#" (semantic-release major versions) and "##" headings are accepted.
+	HeadingLevel int
+	// DateLayouts are additional time layouts tried before the defaults when
+	// parsing release dates.
+	DateLayouts []string
+	// OriginHosts maps self-hosted hostnames to their provider and API URL.
+	OriginHosts map[string]origin.HostConfig
+	// OriginProbe detects the provider of unknown hosts by querying their APIs.
+	OriginProbe bool
+	// OriginDiscoverToken looks up a token from the environment and ~/.netrc
+	// when OriginToken is empty.
+	OriginDiscoverToken bool
+	// OriginGitCredential also asks git credential helpers for a token.
+	OriginGitCredential bool
+	// GitHubApp, when set, authenticates GitHub requests as an app installation.
+	GitHubApp *origin.GitHubApp
+	// Workers is the number of related items fetched concurrently. When zero,
+	// DefaultWorkers is used.
+	Workers int
+	// HTTPClient sends requests for related items. When nil, the providers'
+	// shared retrying client is used.
+	HTTPClient *http.Client
+	// Cache, when set, stores fetched related items on disk between runs.
+	Cache *origin.Cache
 }
 
 func NewParser() *Parser {
@@ -62,6 +121,22 @@ func (p *Parser) GetVersion(version string) (*ChangelogEntry, error) {
 	return nil, fmt.Errorf("version %s not found", version)
 }
 
+// FilterComponent returns the entries belonging to the given component.
+// An empty component returns entries unchanged.
+func FilterComponent(entries []ChangelogEntry, component string) []ChangelogEntry {
+	if component == "" {
+		return entries
+	}
+
+	var filtered []ChangelogEntry
+	for _, entry := range entries {
+		if entry.Component == component {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
 func (p *Parser) Parse(content string) ([]ChangelogEntry, error) {
 	scanner := bufio.NewScanner(strings.NewReader(content))
 	var currentEntry *ChangelogEntry
@@ -71,8 +146,15 @@ func (p *Parser) Parse(content string) ([]ChangelogEntry, error) {
 	versionRegex := regexp.MustCompile(versionPattern)
 	changeRegex := regexp.MustCompile(changePattern)
 
+	if (p.FetchItemDetails || p.IncludeBody || p.IncludeCommitDetails) && p.Repo == nil {
+		p.Repo, err = git.Open(".")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if p.FetchItemDetails {
-		p.originUrl, err = git.GetOriginURL(".")
+		p.originUrl, err = p.Repo.OriginURL()
 		if err != nil {
 			return nil, fmt.Errorf("failed to get origin URL: %w", err)
 		}
@@ -85,20 +167,33 @@ func (p *Parser) Parse(content string) ([]ChangelogEntry, error) {
 			continue
 		}
 
-		if matches := versionRegex.FindStringSubmatch(line); matches != nil {
+		level, heading := parseHeading(line)
+
+		if matches := versionRegex.FindStringSubmatch(line); matches != nil &&
+			p.isVersionLevel(level) {
+			entry, err := p.createNewEntry(matches)
+			if err != nil && !errors.Is(err, errNotRelease) {
+				return nil, err
+			}
 			if currentEntry != nil {
 				p.entries = append(p.entries, *currentEntry)
 			}
+			// headings such as "## 1.0 Upgrade notes" aren't releases, but
+			// still end the previous one so their content doesn't leak into it
+			currentEntry = entry
+			continue
+		}
 
-			currentEntry, err = p.createNewEntry(matches)
-			if err != nil {
-				return nil, err
-			}
+		// a heading above the release level (e.g. "#" when releases are "##")
+		// ends the current release rather than leaking into it
+		if level > 0 && level < p.sectionLevel()-1 && currentEntry != nil {
+			p.entries = append(p.entries, *currentEntry)
+			currentEntry = nil
 			continue
 		}
 
-		if strings.HasPrefix(line, "### ") {
-			currentSection = strings.TrimPrefix(line, "### ")
+		if level == p.sectionLevel() {
+			currentSection = heading
 			continue
 		}
 
@@ -113,23 +208,111 @@ func (p *Parser) Parse(content string) ([]ChangelogEntry, error) {
 		p.entries = append(p.entries, *currentEntry)
 	}
 
+	if p.FetchItemDetails {
+		if err := p.fetchRelatedItems(); err != nil {
+			return nil, err
+		}
+	}
+
 	return p.entries, nil
 }
 
+// createNewEntry creates the entry for a version heading. It returns
+// errNotRelease when the text after the version isn't a date.
 func (p *Parser) createNewEntry(matches []string) (*ChangelogEntry, error) {
-	date, err := time.Parse(dateFormat, matches[3])
+	rest := matches[5]
+	yanked, yankedReason := false, ""
+	if loc := yankedRegex.FindStringSubmatchIndex(rest); loc != nil {
+		yanked = true
+		if loc[2] >= 0 {
+			yankedReason = strings.TrimSpace(rest[loc[2]:loc[3]])
+		}
+		rest = rest[:loc[0]] + rest[loc[1]:]
+	}
+
+	date, undated, err := p.parseDate(rest)
 	if err != nil {
-		return nil, fmt.Errorf("invalid date format: %w", err)
+		return nil, err
 	}
 
 	return &ChangelogEntry{
-		Version:    matches[1],
-		Date:       date,
-		CompareURL: matches[2],
-		Changes:    make(map[string][]Change),
+		Component:    matches[2],
+		Version:      matches[3],
+		Date:         date,
+		Undated:      undated,
+		Yanked:       yanked,
+		YankedReason: yankedReason,
+		CompareURL:   matches[4],
+		Changes:      make(map[string][]Change),
 	}, nil
 }
 
+// parseDate parses the date at the start of the text following a version
+// heading, e.g. "(2025-01-14)", "- 2025-01-14" or "January 14, 2025"; any
+// text after it, such as a release name, is ignored. Empty text marks the
+// release as undated. Text that doesn't look like a date, such as "Upgrade
+// notes", returns errNotRelease, while a parenthesised or numeric date in an
+// unknown layout is an error.
+func (p *Parser) parseDate(text string) (time.Time, bool, error) {
+	text = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(text), "-–—:"))
+	if text == "" {
+		return time.Time{}, true, nil
+	}
+
+	candidate := text
+	parenthesised := strings.HasPrefix(text, "(")
+	if parenthesised {
+		candidate, _, _ = strings.Cut(text[1:], ")")
+		candidate = strings.TrimSpace(candidate)
+	}
+
+	// try the longest run of leading words that parses, e.g. the first three
+	// of "January 14, 2025 - Codename"
+	words := strings.Fields(candidate)
+	for n := len(words); n > 0; n-- {
+		prefix := strings.Join(words[:n], " ")
+		for _, layout := range slices.Concat(p.DateLayouts, defaultDateLayouts) {
+			if date, err := time.Parse(layout, prefix); err == nil {
+				return date, false, nil
+			}
+		}
+	}
+
+	if !parenthesised && (len(words) == 0 || !strings.ContainsAny(words[0], "0123456789")) {
+		return time.Time{}, false, errNotRelease
+	}
+	return time.Time{}, false, fmt.Errorf("invalid date format: %q", candidate)
+}
+
+// isVersionLevel reports whether a heading at the given level may hold a release.
+func (p *Parser) isVersionLevel(level int) bool {
+	if p.HeadingLevel == 0 {
+		return level == 1 || level == 2
+	}
+	return level == p.HeadingLevel
+}
+
+// sectionLevel returns the heading level used for change sections (e.g. "### Features").
+func (p *Parser) sectionLevel() int {
+	if p.HeadingLevel == 0 {
+		return 3
+	}
+	return p.HeadingLevel + 1
+}
+
+// parseHeading returns the level and text of a markdown heading, or zero if
+// the line is not a heading.
+func parseHeading(line string) (int, string) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || level >= len(line) || line[level] != ' ' {
+		return 0, ""
+	}
+	return level, strings.TrimSpace(line[level:])
+}
+
 func (p *Parser) parseChange(
 	line string,
 	changeRegex *regexp.Regexp,
@@ -141,28 +324,19 @@ func (p *Parser) parseChange(
 		return nil
 	}
 
-	relatedItems, err := extractRelatedItems(matches[2], p.originUrl, p.OriginToken)
-	if err != nil {
-		return err
-	}
-
 	change := Change{
 		Scope:        matches[1],
 		Description:  matches[2],
-		RelatedItems: relatedItems,
+		RelatedItems: extractRelatedItems(matches[2]),
 	}
 
 	if matches[3] != "" {
 		change.Commit = parseCommitHashFromLink(matches[3])
-		if err := p.addCommitBody(&change); err != nil {
+		if err := p.addCommitInfo(&change); err != nil {
 			return err
 		}
 		if change.CommitBody != "" {
-			bodyItems, err := extractRelatedItems(change.CommitBody, p.originUrl, p.OriginToken)
-			if err != nil {
-				return err
-			}
-			for _, item := range bodyItems {
+			for _, item := range extractRelatedItems(change.CommitBody) {
 				if !containsIssue(change.RelatedItems, item) {
 					change.RelatedItems = append(change.RelatedItems, item)
 				}
@@ -179,16 +353,23 @@ func (p *Parser) parseChange(
 	return nil
 }
 
-func (p *Parser) addCommitBody(change *Change) error {
-	if !p.IncludeBody || change.Commit == "" {
+func (p *Parser) addCommitInfo(change *Change) error {
+	if (!p.IncludeBody && !p.IncludeCommitDetails) || change.Commit == "" {
 		return nil
 	}
 
-	body, err := git.GetCommmitBodyFromSha(".", change.Commit)
+	commit, err := p.Repo.Commit(change.Commit)
 	if err != nil {
 		return fmt.Errorf("failed to get commit message: %w", err)
 	}
-	change.CommitBody = body
+	if p.IncludeBody {
+		change.CommitBody = commit.Body()
+		change.Trailers = commit.Trailers()
+		change.Contributors = commit.Authors()
+	}
+	if p.IncludeCommitDetails {
+		change.CommitDetails = commit
+	}
 	return nil
 }
 
@@ -207,50 +388,25 @@ func parseCommitHashFromLink(link string) string {
 	return ""
 }
 
-func extractRelatedItems(text string, repoUrl string, token string) ([]*origin.Issue, error) {
-	issueRegex := regexp.MustCompile(`([#!])(\d+)`)
+// extractRelatedItems returns an item for each distinct issue or pull request
+// token in text: "#N", "!N" or a Bitbucket "pull-requests/N" link. Details are
+// fetched once the whole changelog has been parsed.
+func extractRelatedItems(text string) []*origin.Issue {
+	issueRegex := regexp.MustCompile(`([#!]\d+)|(?:^|/)(pull-requests/\d+)`)
 	matches := issueRegex.FindAllStringSubmatch(text, -1)
 
 	seen := make(map[string]bool)
 	var items []*origin.Issue
 
-	if repoUrl != "" {
-		provider, err := origin.NewIssueProvider(origin.Config{URL: repoUrl, Token: token})
-		if err != nil {
-			return nil, fmt.Errorf("failed to create issue provider: %w", err)
-		}
-
-		for _, match := range matches {
-			prefix := match[1]
-			numStr := match[2]
-			fullToken := prefix + numStr
-			if !seen[fullToken] {
-				issue, err := provider.GetIssue(fullToken)
-				if err != nil {
-					return nil, fmt.Errorf("failed to get related item details for %s: %w",
-						fullToken, err)
-				}
-				if issue != nil {
-					issue.Number = fullToken // preserve original token with prefix
-				}
-				items = append(items, issue)
-				seen[fullToken] = true
-			}
-		}
-	} else {
-		// When repoUrl is empty, just create basic Issue objects with tokens
-		for _, match := range matches {
-			prefix := match[1]
-			numStr := match[2]
-			fullToken := prefix + numStr
-			if !seen[fullToken] {
-				items = append(items, &origin.Issue{Number: fullToken})
-				seen[fullToken] = true
-			}
+	for _, match := range matches {
+		fullToken := match[1] + match[2]
+		if !seen[fullToken] {
+			items = append(items, &origin.Issue{Number: fullToken})
+			seen[fullToken] = true
 		}
 	}
 
-	return items, nil
+	return items
 }
 
 func containsIssue(items []*origin.Issue, item *origin.Issue) bool {
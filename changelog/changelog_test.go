@@ -1,10 +1,25 @@
 package changelog
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
 	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"cl-parse/git"
 	"cl-parse/origin"
 )
 
@@ -160,6 +175,41 @@ func TestParse(t *testing.T) {
 				),
 			},
 		},
+		{
+			name: "parses component-prefixed versions",
+			input: `# Changelog
+## [my-pkg-v1.2.0](https://github.com/user/repo/compare/my-pkg-v1.1.0...my-pkg-v1.2.0) (2025-01-02)
+
+### Features
+
+* pkg feature
+
+## other-pkg: 0.3.0 (2025-01-01)
+
+### Bug Fixes
+
+* other fix
+`,
+			want: []ChangelogEntry{
+				{
+					Component:  "my-pkg",
+					Version:    "1.2.0",
+					Date:       mustParseTime("2025-01-02"),
+					CompareURL: "https://github.com/user/repo/compare/my-pkg-v1.1.0...my-pkg-v1.2.0",
+					Changes: map[string][]Change{
+						"Features": {createTestChange("pkg feature", "", "", nil)},
+					},
+				},
+				{
+					Component: "other-pkg",
+					Version:   "0.3.0",
+					Date:      mustParseTime("2025-01-01"),
+					Changes: map[string][]Change{
+						"Bug Fixes": {createTestChange("other fix", "", "", nil)},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -291,6 +341,959 @@ func TestEmptyChangelog(t *testing.T) {
 	})
 }
 
+func TestParseHeadingLevels(t *testing.T) {
+	const semanticRelease = `# Changelog
+
+## [2.1.0](https://github.com/user/repo/compare/v2.0.0...v2.1.0) (2025-02-01)
+
+### Features
+
+* minor feature
+
+# [2.0.0](https://github.com/user/repo/compare/v1.0.0...v2.0.0) (2025-01-15)
+
+### Features
+
+* major feature
+`
+
+	const nestedReleases = `# Project
+
+## Releases
+
+### 1.1.0 (2025-02-01)
+
+#### Bug Fixes
+
+* nested fix
+`
+
+	tests := []struct {
+		name         string
+		input        string
+		headingLevel int
+		wantVersions []string
+		wantSection  string
+	}{
+		{
+			name:         "auto-detects semantic-release major headings",
+			input:        semanticRelease,
+			wantVersions: []string{"2.1.0", "2.0.0"},
+			wantSection:  "Features",
+		},
+		{
+			name:         "fixed level ignores other headings",
+			input:        semanticRelease,
+			headingLevel: 2,
+			wantVersions: []string{"2.1.0"},
+			wantSection:  "Features",
+		},
+		{
+			name:         "custom level shifts section headings",
+			input:        nestedReleases,
+			headingLevel: 3,
+			wantVersions: []string{"1.1.0"},
+			wantSection:  "Bug Fixes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser()
+			p.HeadingLevel = tt.headingLevel
+			entries, err := p.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+
+			var versions []string
+			for _, entry := range entries {
+				versions = append(versions, entry.Version)
+				if len(entry.Changes[tt.wantSection]) != 1 {
+					t.Errorf("version %s: expected one change in %q, got %+v",
+						entry.Version, tt.wantSection, entry.Changes)
+				}
+			}
+			if !reflect.DeepEqual(versions, tt.wantVersions) {
+				t.Errorf("versions = %v, want %v", versions, tt.wantVersions)
+			}
+		})
+	}
+}
+
+func TestParseDates(t *testing.T) {
+	tests := []struct {
+		name        string
+		heading     string
+		layouts     []string
+		want        time.Time
+		wantUndated bool
+		wantErr     bool
+	}{
+		{name: "parenthesised iso", heading: "## 1.0.0 (2025-01-14)", want: mustParseTime("2025-01-14")},
+		{name: "keep a changelog", heading: "## [1.0.0] - 2025-01-14", want: mustParseTime("2025-01-14")},
+		{name: "slashes", heading: "## 1.0.0 2025/01/14", want: mustParseTime("2025-01-14")},
+		{name: "long month", heading: "## v1.0.0 - January 14, 2025", want: mustParseTime("2025-01-14")},
+		{name: "day first", heading: "## 1.0.0 (14 Jan 2025)", want: mustParseTime("2025-01-14")},
+		{
+			name:    "rfc3339 with zone",
+			heading: "## 1.0.0 - 2025-01-14T09:30:00+13:00",
+			want:    time.Date(2025, 1, 14, 9, 30, 0, 0, time.FixedZone("", 13*60*60)),
+		},
+		{
+			name:    "custom layout",
+			heading: "## 1.0.0 (14.01.2025)",
+			layouts: []string{"02.01.2006"},
+			want:    mustParseTime("2025-01-14"),
+		},
+		{name: "undated", heading: "## [1.0.0](https://example.com)", wantUndated: true},
+		{
+			name:    "trailing release name",
+			heading: `## [1.0.0](https://example.com) (2025-01-14) "Codename"`,
+			want:    mustParseTime("2025-01-14"),
+		},
+		{name: "long month then name", heading: "## 1.0.0 - January 14, 2025 - Codename", want: mustParseTime("2025-01-14")},
+		{name: "unrecognised date", heading: "## 1.0.0 (someday)", wantErr: true},
+		{name: "unknown layout", heading: "## [1.0.0] - 14.01.2025", wantErr: true},
+		{name: "invalid date", heading: "## [1.0.0] (2025-13-45)", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser()
+			p.DateLayouts = tt.layouts
+			entries, err := p.Parse(tt.heading + "\n### Features\n* feature\n")
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if len(entries) != 1 {
+				t.Fatalf("expected 1 entry, got %d", len(entries))
+			}
+
+			entry := entries[0]
+			if entry.Version != "1.0.0" {
+				t.Errorf("Version = %q, want 1.0.0", entry.Version)
+			}
+			if !entry.Date.Equal(tt.want) || entry.Undated != tt.wantUndated {
+				t.Errorf("Date = %v (undated %v), want %v (undated %v)",
+					entry.Date, entry.Undated, tt.want, tt.wantUndated)
+			}
+		})
+	}
+}
+
+func TestParseSkipsProseVersionHeadings(t *testing.T) {
+	const input = `# Changelog
+
+## [1.1.0] - 2025-02-01
+
+### Features
+
+* new feature
+
+## 1.0 Upgrade notes
+
+Read these before upgrading.
+
+### Breaking Changes
+
+* not a change in 1.1.0
+
+## [1.0.0] - 2025-01-01
+
+### Features
+
+* initial release
+`
+
+	entries, err := NewParser().Parse(input)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Version != "1.1.0" || entries[1].Version != "1.0.0" {
+		t.Errorf("versions = %s, %s, want 1.1.0, 1.0.0", entries[0].Version, entries[1].Version)
+	}
+	if len(entries[0].Changes) != 1 || len(entries[1].Changes) != 1 {
+		t.Errorf("changes = %v and %v, want only each release's own features",
+			entries[0].Changes, entries[1].Changes)
+	}
+}
+
+func TestParseYanked(t *testing.T) {
+	const input = `# Changelog
+
+## [1.0.2] - 2025-01-03
+
+### Fixed
+
+* fix
+
+## [1.0.1] - 2025-01-02 [YANKED]
+
+### Fixed
+
+* broken fix
+
+## [1.0.0] - 2025-01-01 [YANKED: data loss on upgrade]
+
+### Added
+
+* initial release
+`
+
+	p := NewParser()
+	entries, err := p.Parse(input)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	want := []struct {
+		yanked bool
+		reason string
+	}{{false, ""}, {true, ""}, {true, "data loss on upgrade"}}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %d", len(want), len(entries))
+	}
+	for i, entry := range entries {
+		if entry.Yanked != want[i].yanked || entry.YankedReason != want[i].reason {
+			t.Errorf("%s: yanked = %v (%q), want %v (%q)",
+				entry.Version, entry.Yanked, entry.YankedReason, want[i].yanked, want[i].reason)
+		}
+		if !entry.Date.Equal(mustParseTime(fmt.Sprintf("2025-01-0%d", 3-i))) {
+			t.Errorf("%s: unexpected date %v", entry.Version, entry.Date)
+		}
+	}
+}
+
+func TestApplyRetractions(t *testing.T) {
+	const gomod = `module example.com/mod
+
+go 1.23
+
+retract v1.0.1 // contains a critical bug
+
+retract [v1.1.0, v1.1.3]
+`
+
+	entries := []ChangelogEntry{
+		{Version: "1.2.0"},
+		{Version: "1.1.2"},
+		{Version: "1.0.1"},
+		{Version: "1.0.0"},
+	}
+	if err := ApplyRetractions(entries, []byte(gomod)); err != nil {
+		t.Fatalf("ApplyRetractions failed: %v", err)
+	}
+
+	var got []string
+	for _, entry := range ExcludeYanked(entries) {
+		got = append(got, entry.Version)
+	}
+	if want := []string{"1.2.0", "1.0.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExcludeYanked() = %v, want %v", got, want)
+	}
+	if entries[2].YankedReason != "contains a critical bug" {
+		t.Errorf("YankedReason = %q, want rationale from go.mod", entries[2].YankedReason)
+	}
+}
+
+func TestVerifyTags(t *testing.T) {
+	entries := []ChangelogEntry{
+		{Component: "api", Version: "1.2.0", Date: mustParseTime("2025-03-01")},
+		{Component: "api", Version: "1.1.0", Date: mustParseTime("2025-02-01")},
+		{Component: "api", Version: "1.0.0", Undated: true},
+	}
+	tags := []git.Tag{
+		{Name: "api-v1.2.0", Date: time.Date(2025, 3, 1, 22, 0, 0, 0, time.UTC)},
+		{Name: "api-v1.1.0", Date: mustParseTime("2025-02-10")},
+		{Name: "api-v0.9.0", Date: mustParseTime("2025-01-01")},
+		{Name: "web-v3.0.0", Date: mustParseTime("2025-01-01")},
+		{Name: "nightly", Date: mustParseTime("2025-01-01")},
+	}
+
+	report := VerifyTags(entries, tags, "{component}-v{version}", "api", 1)
+
+	if want := []string{"api-v0.9.0"}; !reflect.DeepEqual(report.MissingEntries, want) {
+		t.Errorf("MissingEntries = %v, want %v", report.MissingEntries, want)
+	}
+	if want := []string{"api-v1.0.0"}; !reflect.DeepEqual(report.MissingTags, want) {
+		t.Errorf("MissingTags = %v, want %v", report.MissingTags, want)
+	}
+	if len(report.DateMismatches) != 1 || report.DateMismatches[0].Tag != "api-v1.1.0" {
+		t.Errorf("DateMismatches = %+v, want only api-v1.1.0", report.DateMismatches)
+	}
+	if report.OK() {
+		t.Error("OK() = true, want false")
+	}
+
+	clean := VerifyTags(entries[:1], tags[:1], "{component}-v{version}", "", 0)
+	if !clean.OK() {
+		t.Errorf("expected clean report, got %+v", clean)
+	}
+}
+
+func TestVerifyCommits(t *testing.T) {
+	lost := "1111111111111111111111111111111111111111"
+
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T) (*git.Repository, []ChangelogEntry)
+		checked int
+		want    map[string]string
+	}{
+		{
+			name: "tagged releases",
+			setup: func(t *testing.T) (*git.Repository, []ChangelogEntry) {
+				dir := t.TempDir()
+				repo, err := gogit.PlainInit(dir, false)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				first := commitFile(t, repo, dir, "feat: first")
+				tagCommit(t, repo, "v1.0.0", first)
+				second := commitFile(t, repo, dir, "feat: second")
+				tagCommit(t, repo, "v1.1.0", second)
+				unreleased := commitFile(t, repo, dir, "feat: unreleased")
+
+				return openRepo(t, dir), []ChangelogEntry{
+					{Version: "1.1.0", Changes: map[string][]Change{"Features": {
+						{Description: "second", Commit: second},
+						{Description: "first again", Commit: first},
+						{Description: "not yet tagged", Commit: unreleased},
+						{Description: "rebased away", Commit: lost},
+					}}},
+					{Version: "1.0.0", Changes: map[string][]Change{"Features": {
+						{Description: "first", Commit: first},
+					}}},
+				}
+			},
+			checked: 5,
+			want: map[string]string{
+				"first again":    CommitInPrevRelease,
+				"not yet tagged": CommitNotInRelease,
+				"rebased away":   CommitMissing,
+			},
+		},
+		{
+			// v1.1.0 was never tagged, so v1.2.0 is checked against v1.0.0.
+			name: "missing previous tag",
+			setup: func(t *testing.T) (*git.Repository, []ChangelogEntry) {
+				r, commits := untaggedReleaseRepo(t)
+				return r, []ChangelogEntry{
+					{Version: "1.2.0", Changes: map[string][]Change{"Features": {
+						{Description: "third", Commit: commits[2]},
+						{Description: "first again", Commit: commits[0]},
+					}}},
+					{Version: "1.1.0", Changes: map[string][]Change{"Features": {
+						{Description: "second", Commit: commits[1]},
+					}}},
+					{Version: "1.0.0", Changes: map[string][]Change{"Features": {
+						{Description: "first", Commit: commits[0]},
+					}}},
+				}
+			},
+			checked: 4,
+			want:    map[string]string{"first again": CommitInPrevRelease},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, entries := tt.setup(t)
+			report, err := VerifyCommits(r, entries, DefaultTagTemplate, "")
+			if err != nil {
+				t.Fatalf("VerifyCommits failed: %v", err)
+			}
+
+			if report.Checked != tt.checked {
+				t.Errorf("Checked = %d, want %d", report.Checked, tt.checked)
+			}
+			got := make(map[string]string)
+			for _, problem := range report.Problems {
+				got[problem.Description] = problem.Problem
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Problems = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(t *testing.T) (*git.Repository, []ChangelogEntry, string)
+		previousTag string
+		tag         string
+		commits     int
+		covered     int
+		unlinked    []string
+	}{
+		{
+			name: "tagged releases",
+			setup: func(t *testing.T) (*git.Repository, []ChangelogEntry, string) {
+				dir := t.TempDir()
+				repo, err := gogit.PlainInit(dir, false)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				first := commitFile(t, repo, dir, "feat: first")
+				tagCommit(t, repo, "v1.0.0", first)
+				covered := commitFile(t, repo, dir, "fix(ui): covered fix")
+				commitFile(t, repo, dir, "chore: not user facing")
+				missing := commitFile(t, repo, dir, "refactor!: drop old api")
+				second := commitFile(t, repo, dir, "docs: release notes")
+				tagCommit(t, repo, "v1.1.0", second)
+
+				return openRepo(t, dir), []ChangelogEntry{
+					{Version: "1.2.0"},
+					{Version: "1.1.0", Changes: map[string][]Change{
+						"Bug Fixes": {{Description: "covered fix", Commit: covered}},
+						"Features":  {{Description: "hand written note"}},
+					}},
+					{Version: "1.0.0", Changes: map[string][]Change{
+						"Features": {{Description: "first", Commit: first}},
+					}},
+				}, missing
+			},
+			previousTag: "v1.0.0",
+			tag:         "v1.1.0",
+			commits:     2,
+			covered:     1,
+			unlinked:    []string{"hand written note"},
+		},
+		{
+			// v1.1.0 was never tagged, so v1.2.0 covers the commits since v1.0.0.
+			name: "missing previous tag",
+			setup: func(t *testing.T) (*git.Repository, []ChangelogEntry, string) {
+				r, commits := untaggedReleaseRepo(t)
+				return r, []ChangelogEntry{
+					{Version: "1.2.0", Changes: map[string][]Change{
+						"Bug Fixes": {{Description: "third", Commit: commits[2]}},
+					}},
+					{Version: "1.1.0", Changes: map[string][]Change{
+						"Features": {{Description: "second", Commit: commits[1]}},
+					}},
+					{Version: "1.0.0", Changes: map[string][]Change{
+						"Features": {{Description: "first", Commit: commits[0]}},
+					}},
+				}, commits[1]
+			},
+			previousTag: "v1.0.0",
+			tag:         "v1.2.0",
+			commits:     2,
+			covered:     1,
+			unlinked:    []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, entries, missing := tt.setup(t)
+			report, err := Coverage(r, entries, DefaultTagTemplate, "", DefaultCoverageTypes)
+			if err != nil {
+				t.Fatalf("Coverage failed: %v", err)
+			}
+
+			if len(report.Releases) != 2 {
+				t.Fatalf("expected untagged release to be skipped, got %+v", report.Releases)
+			}
+			release := report.Releases[0]
+			if release.Tag != tt.tag || release.PreviousTag != tt.previousTag {
+				t.Errorf("release tags = %s..%s, want %s..%s",
+					release.PreviousTag, release.Tag, tt.previousTag, tt.tag)
+			}
+			if release.Commits != tt.commits || release.Covered != tt.covered {
+				t.Errorf("release coverage = %d/%d, want %d/%d",
+					release.Covered, release.Commits, tt.covered, tt.commits)
+			}
+			if len(release.MissingCommits) != 1 || release.MissingCommits[0].Commit != missing {
+				t.Errorf("MissingCommits = %+v, want %s", release.MissingCommits, missing)
+			}
+			if !reflect.DeepEqual(release.UnlinkedChanges, tt.unlinked) {
+				t.Errorf("UnlinkedChanges = %v, want %v", release.UnlinkedChanges, tt.unlinked)
+			}
+			if report.Commits != 3 || report.Covered != 2 {
+				t.Errorf("overall coverage = %d/%d, want 2/3", report.Covered, report.Commits)
+			}
+		})
+	}
+}
+
+func TestWhich(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := commitFile(t, repo, dir, "feat: first\n\nCloses #7")
+	tagCommit(t, repo, "v1.0.0", first)
+	second := commitFile(t, repo, dir, "fix: second")
+	tagCommit(t, repo, "v1.1.0", second)
+
+	entries := []ChangelogEntry{
+		{Version: "1.1.0", Changes: map[string][]Change{"Bug Fixes": {
+			{Description: "fix #12 again", RelatedItems: []*origin.Issue{{Number: "#12"}}},
+		}}},
+		{Version: "1.0.0", Changes: map[string][]Change{"Features": {
+			{Description: "first", Commit: first},
+			{Description: "fix #12", RelatedItems: []*origin.Issue{{Number: "#12"}}},
+		}}},
+	}
+
+	if got := FindInChangelog(entries, "#12"); got == nil || got.Version != "1.0.0" {
+		t.Errorf("FindInChangelog(#12) = %+v, want earliest release 1.0.0", got)
+	}
+	if got := FindInChangelog(entries, first[:7]); got == nil || got.Commit != first {
+		t.Errorf("FindInChangelog(short sha) = %+v, want commit %s", got, first)
+	}
+	if got := FindInChangelog(entries, second); got != nil {
+		t.Errorf("FindInChangelog(unlisted sha) = %+v, want nil", got)
+	}
+
+	r, err := git.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := FindInHistory(r, entries, DefaultTagTemplate, "", second[:8])
+	if err != nil || got.Version != "1.1.0" || got.Source != SourceGit || got.Commit != second {
+		t.Errorf("FindInHistory(short sha) = %+v, %v; want 1.1.0 via git", got, err)
+	}
+	got, err = FindInHistory(r, entries, DefaultTagTemplate, "", "#7")
+	if err != nil || got.Version != "1.0.0" || got.Commit != first {
+		t.Errorf("FindInHistory(#7) = %+v, %v; want 1.0.0", got, err)
+	}
+	if _, err := FindInHistory(r, entries, DefaultTagTemplate, "", "#99"); err == nil {
+		t.Error("FindInHistory(#99) expected error for unknown issue")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	base := []ChangelogEntry{
+		{Version: "1.1.0", Date: mustParseTime("2024-02-01"), Changes: map[string][]Change{
+			"Bug Fixes": {{Description: "fix crash", Commit: "abc1234"}},
+		}},
+		{Version: "1.0.0", Date: mustParseTime("2024-01-01"), Changes: map[string][]Change{
+			"Features": {{Description: "first"}, {Description: "second", Commit: "def5678"}},
+		}},
+		{Version: "0.9.0", Date: mustParseTime("2023-12-01")},
+	}
+	head := []ChangelogEntry{
+		{Version: "1.2.0", Date: mustParseTime("2024-03-01"), Changes: map[string][]Change{
+			"Features": {{Description: "new"}},
+		}},
+		{Version: "1.1.0", Date: mustParseTime("2024-02-01"), Yanked: true, YankedReason: "broken", Changes: map[string][]Change{
+			"Bug Fixes": {{Description: "fix crash", Commit: "abc1234"}},
+		}},
+		{Version: "1.0.0", Date: mustParseTime("2024-01-02"), Changes: map[string][]Change{
+			"Features": {{Description: "first"}, {Description: "second (reworded)", Commit: "def5678"}},
+		}},
+	}
+
+	report := Diff(base, head)
+	if report.OK() {
+		t.Fatal("expected edits to released sections to be reported as errors")
+	}
+
+	byVersion := make(map[string]VersionDiff)
+	for _, version := range report.Versions {
+		byVersion[version.Version] = version
+	}
+	if len(byVersion) != 4 {
+		t.Fatalf("got %d versions in diff, want 4: %+v", len(byVersion), report.Versions)
+	}
+
+	if added := byVersion["1.2.0"]; added.Status != DiffAdded || added.Error || len(added.Added) != 1 {
+		t.Errorf("1.2.0 = %+v, want added without error", added)
+	}
+	if yanked := byVersion["1.1.0"]; yanked.Status != DiffModified || yanked.Error || len(yanked.Fields) != 2 {
+		t.Errorf("1.1.0 = %+v, want yanked fields without error", yanked)
+	}
+
+	modified := byVersion["1.0.0"]
+	if !modified.Error || len(modified.Fields) != 1 || modified.Fields[0].Field != "date" {
+		t.Errorf("1.0.0 fields = %+v, want date change flagged as error", modified.Fields)
+	}
+	if len(modified.Modified) != 1 || modified.Modified[0].After.Description != "second (reworded)" {
+		t.Errorf("1.0.0 modified = %+v, want reworded change matched by commit", modified.Modified)
+	}
+	if len(modified.Added) != 0 || len(modified.Removed) != 0 {
+		t.Errorf("1.0.0 added/removed = %+v/%+v, want none", modified.Added, modified.Removed)
+	}
+
+	if removed := byVersion["0.9.0"]; removed.Status != DiffRemoved || !removed.Error {
+		t.Errorf("0.9.0 = %+v, want removed with error", removed)
+	}
+
+	if report := Diff(base, base); !report.OK() || len(report.Versions) != 0 {
+		t.Errorf("Diff(base, base) = %+v, want no changes", report)
+	}
+}
+
+func TestAddContributors(t *testing.T) {
+	taggedRepo := func(t *testing.T) *git.Repository {
+		dir := t.TempDir()
+		repo, err := gogit.PlainInit(dir, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		first := commitFile(t, repo, dir, "feat: first")
+		tagCommit(t, repo, "v1.0.0", first)
+		commitFile(t, repo, dir, "fix: pair\n\nCo-authored-by: Alice <alice@example.com>")
+		second := commitFile(t, repo, dir, "fix: again\n\nCo-authored-by: Test <TEST@example.com>")
+		tagCommit(t, repo, "v1.1.0", second)
+		return openRepo(t, dir)
+	}
+	untaggedRepo := func(t *testing.T) *git.Repository {
+		r, _ := untaggedReleaseRepo(t)
+		return r
+	}
+
+	alice := Contributor{Name: "Alice", Email: "alice@example.com", Commits: 1, FirstTime: true}
+	bob := Contributor{Name: "Bob", Email: "bob@example.com", Commits: 1, FirstTime: true}
+	tests := []struct {
+		name  string
+		setup func(t *testing.T) *git.Repository
+		n     int
+		want  [][]Contributor // By entry: 1.2.0, 1.1.0, 1.0.0
+	}{
+		{
+			name:  "tagged releases",
+			setup: taggedRepo,
+			n:     3,
+			want: [][]Contributor{
+				nil,
+				{alice, {Name: "test", Email: "test@example.com", Commits: 2}},
+				{{Name: "test", Email: "test@example.com", Commits: 1, FirstTime: true}},
+			},
+		},
+		{
+			// Older releases are only used as boundaries.
+			name:  "newest releases only",
+			setup: taggedRepo,
+			n:     2,
+			want: [][]Contributor{
+				nil,
+				{alice, {Name: "test", Email: "test@example.com", Commits: 2}},
+				nil,
+			},
+		},
+		{
+			// v1.1.0 was never tagged, so v1.2.0 credits the commits since v1.0.0.
+			name:  "missing previous tag",
+			setup: untaggedRepo,
+			n:     3,
+			want: [][]Contributor{
+				{bob, {Name: "test", Email: "test@example.com", Commits: 2}},
+				nil,
+				{alice, {Name: "test", Email: "test@example.com", Commits: 1, FirstTime: true}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := []ChangelogEntry{{Version: "1.2.0"}, {Version: "1.1.0"}, {Version: "1.0.0"}}
+			if err := AddContributors(tt.setup(t), entries, tt.n, DefaultTagTemplate, ""); err != nil {
+				t.Fatal(err)
+			}
+			for i, want := range tt.want {
+				if !reflect.DeepEqual(entries[i].Contributors, want) {
+					t.Errorf("%s contributors = %+v, want %+v", entries[i].Version, entries[i].Contributors, want)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractRelatedItems(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"fix #1 and !2, again #1", []string{"#1", "!2"}},
+		{"merge https://bitbucket.org/ws/repo/pull-requests/12 (#3)", []string{"pull-requests/12", "#3"}},
+		{"see my-pull-requests/4", nil},
+		{"no references", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			var got []string
+			for _, item := range extractRelatedItems(tt.text) {
+				got = append(got, item.Number)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractRelatedItems() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchRelatedItems(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+
+		var number int
+		if _, err := fmt.Sscanf(path.Base(r.URL.Path), "%d", &number); err != nil || number == 404 {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"number":%d,"title":"Item %d"}`, number, number)
+	}))
+	defer server.Close()
+
+	content := `## [1.1.0](https://example.com) (2024-02-01)
+
+### Features
+
+* add thing #1 and #2
+* fix thing !3, #404
+
+## [1.0.0](https://example.com) (2024-01-01)
+
+### Bug Fixes
+
+* earlier fix #2
+`
+
+	parser := NewParser()
+	parser.Repo = originRepo(t, "https://git.example.com/owner/repo.git")
+	parser.FetchItemDetails = true
+	parser.Workers = 3
+	parser.OriginHosts = map[string]origin.HostConfig{
+		"git.example.com": {Kind: origin.KindGitea, APIURL: server.URL + "/api/v1"},
+	}
+
+	entries, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	for path, count := range requests {
+		if count != 1 {
+			t.Errorf("%s fetched %d times, want once", path, count)
+		}
+	}
+	if len(requests) != 4 {
+		t.Errorf("fetched %d distinct items, want 4: %v", len(requests), requests)
+	}
+
+	features := entries[0].Changes["Features"]
+	if got := features[0].RelatedItems; len(got) != 2 || got[0].Title != "Item 1" || got[1].Number != "#2" {
+		t.Errorf("first change related items = %+v", got)
+	}
+	if got := features[1].RelatedItems; len(got) != 2 || got[0].Number != "!3" || got[1] != nil {
+		t.Errorf("second change related items = %+v, want !3 and nil for not found", got)
+	}
+	if got := entries[1].Changes["Bug Fixes"][0].RelatedItems; len(got) != 1 || got[0].Title != "Item 2" {
+		t.Errorf("earlier release related items = %+v", got)
+	}
+
+	features[0].RelatedItems[1].Title = "edited"
+	if got := entries[1].Changes["Bug Fixes"][0].RelatedItems[0].Title; got != "Item 2" {
+		t.Errorf("editing one change's item changed another's title to %q", got)
+	}
+}
+
+func TestFetchRelatedItemsStopsAfterError(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Error(w, "bad credentials", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	var content strings.Builder
+	content.WriteString("## 1.0.0 (2024-01-01)\n\n### Features\n\n")
+	for i := 1; i <= 20; i++ {
+		fmt.Fprintf(&content, "* change #%d\n", i)
+	}
+
+	parser := NewParser()
+	parser.FetchItemDetails = true
+	parser.Workers = 1
+	parser.Repo = originRepo(t, "https://git.example.com/owner/repo.git")
+	parser.OriginHosts = map[string]origin.HostConfig{
+		"git.example.com": {Kind: origin.KindGitea, APIURL: server.URL + "/api/v1"},
+	}
+
+	if _, err := parser.Parse(content.String()); err == nil {
+		t.Fatal("Parse() expected error when fetching fails")
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("made %d requests, want 1 before stopping", got)
+	}
+}
+
+// originRepo creates a repository whose origin remote is url.
+func originRepo(t *testing.T, url string) *git.Repository {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{url}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return openRepo(t, dir)
+}
+
+// commitFile commits a change to a file in the repository and returns the commit hash.
+func commitFile(t *testing.T, repo *gogit.Repository, dir, message string) string {
+	t.Helper()
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "file.txt"), message)
+	if _, err := w.Add("file.txt"); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := w.Commit(message, &gogit.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash.String()
+}
+
+func tagCommit(t *testing.T, repo *gogit.Repository, name, commit string) {
+	t.Helper()
+	if _, err := repo.CreateTag(name, plumbing.NewHash(commit), nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// untaggedReleaseRepo creates a repository where 1.0.0 and 1.2.0 are tagged
+// but 1.1.0, released between them, never was. It returns the three releases'
+// commits, oldest first.
+func untaggedReleaseRepo(t *testing.T) (*git.Repository, [3]string) {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var commits [3]string
+	commits[0] = commitFile(t, repo, dir, "feat: first\n\nCo-authored-by: Alice <alice@example.com>")
+	tagCommit(t, repo, "v1.0.0", commits[0])
+	commits[1] = commitFile(t, repo, dir, "feat: second\n\nCo-authored-by: Bob <bob@example.com>")
+	commits[2] = commitFile(t, repo, dir, "fix: third")
+	tagCommit(t, repo, "v1.2.0", commits[2])
+	return openRepo(t, dir), commits
+}
+
+// openRepo opens the repository at dir.
+func openRepo(t *testing.T, dir string) *git.Repository {
+	t.Helper()
+	r, err := git.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestFilterComponent(t *testing.T) {
+	entries := []ChangelogEntry{
+		{Component: "api", Version: "2.0.0"},
+		{Component: "web", Version: "1.1.0"},
+		{Component: "api", Version: "1.0.0"},
+	}
+
+	var got []string
+	for _, entry := range FilterComponent(entries, "api") {
+		got = append(got, entry.Version)
+	}
+	if want := []string{"2.0.0", "1.0.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterComponent() = %v, want %v", got, want)
+	}
+
+	if all := FilterComponent(entries, ""); len(all) != len(entries) {
+		t.Errorf("FilterComponent() with empty component returned %d entries, want %d",
+			len(all), len(entries))
+	}
+}
+
+func TestLoadPackages(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "release-please-config.json"), `{
+  "packages": {
+    ".": {},
+    "packages/api": {"component": "api"},
+    "packages/web": {"package-name": "@acme/web", "changelog-path": "HISTORY.md"}
+  }
+}`)
+	writeFile(t, filepath.Join(root, ".release-please-manifest.json"),
+		`{".": "1.0.0", "packages/api": "2.1.0", "packages/web": "0.3.0"}`)
+
+	packages, err := LoadPackages(root)
+	if err != nil {
+		t.Fatalf("LoadPackages failed: %v", err)
+	}
+
+	want := []Package{
+		{
+			Path:            ".",
+			ChangelogPath:   filepath.Join(root, "CHANGELOG.md"),
+			ManifestVersion: "1.0.0",
+		},
+		{
+			Path:            "packages/api",
+			Component:       "api",
+			ChangelogPath:   filepath.Join(root, "packages", "api", "CHANGELOG.md"),
+			ManifestVersion: "2.1.0",
+		},
+		{
+			Path:            "packages/web",
+			Component:       "web",
+			ChangelogPath:   filepath.Join(root, "packages", "web", "HISTORY.md"),
+			ManifestVersion: "0.3.0",
+		},
+	}
+	if !reflect.DeepEqual(packages, want) {
+		t.Errorf("\ngot:  %+v\nwant: %+v", packages, want)
+	}
+
+	pkg := packages[1]
+	pkg.SetEntries([]ChangelogEntry{{Version: "2.0.0"}})
+	if pkg.InSync || pkg.LatestVersion != "2.0.0" {
+		t.Errorf("expected out of sync package with latest 2.0.0, got %+v", pkg)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func mustParseTime(date string) time.Time {
 	t, err := time.Parse("2006-01-02", date)
 	if err != nil {
@@ -0,0 +1,142 @@
+package changelog
+
+import (
+	"sort"
+
+	"cl-parse/git"
+)
+
+// Problems reported by VerifyCommits.
+const (
+	CommitMissing       = "missing"             // the commit does not exist, e.g. lost in a rebase
+	CommitNotInRelease  = "not-in-release"      // the release tag does not contain the commit
+	CommitInPrevRelease = "in-previous-release" // an earlier release tag already contains the commit
+)
+
+// CommitReport lists changes whose commits do not belong to their release.
+type CommitReport struct {
+	Checked  int             `json:"checked"  yaml:"checked"  toml:"checked"`
+	Problems []CommitProblem `json:"problems" yaml:"problems" toml:"problems"`
+}
+
+// CommitProblem is a single change whose commit failed verification.
+type CommitProblem struct {
+	Version     string `json:"version"     yaml:"version"     toml:"version"`
+	Tag         string `json:"tag"         yaml:"tag"         toml:"tag"`
+	Commit      string `json:"commit"      yaml:"commit"      toml:"commit"`
+	Description string `json:"description" yaml:"description" toml:"description"`
+	Problem     string `json:"problem"     yaml:"problem"     toml:"problem"`
+}
+
+// OK reports whether every commit belongs to the release it is listed under.
+func (r CommitReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// VerifyCommits checks that each change's commit exists, is contained in its
+// release's tag and is not already contained in the previous release's tag.
+// Ancestry checks are skipped for releases whose tags cannot be found.
+func VerifyCommits(
+	repo *git.Repository,
+	entries []ChangelogEntry,
+	template string,
+	component string,
+) (CommitReport, error) {
+	report := CommitReport{Problems: []CommitProblem{}}
+
+	for i, entry := range entries {
+		tag := TagName(template, entry, component)
+		tagCommit := resolveTag(repo, tag)
+		_, prevCommit := previousTag(repo, entries, i, template, component)
+
+		for _, section := range sortedSections(entry) {
+			for _, change := range entry.Changes[section] {
+				if change.Commit == "" {
+					continue
+				}
+				report.Checked++
+
+				problem := CommitProblem{
+					Version:     entry.Version,
+					Tag:         tag,
+					Commit:      change.Commit,
+					Description: change.Description,
+				}
+
+				if !repo.HasCommit(change.Commit) {
+					problem.Problem = CommitMissing
+					report.Problems = append(report.Problems, problem)
+					continue
+				}
+
+				if tagCommit != "" {
+					inRelease, err := repo.IsAncestor(change.Commit, tagCommit)
+					if err != nil {
+						return report, err
+					}
+					if !inRelease {
+						problem.Problem = CommitNotInRelease
+						report.Problems = append(report.Problems, problem)
+						continue
+					}
+				}
+
+				if prevCommit != "" {
+					inPrevious, err := repo.IsAncestor(change.Commit, prevCommit)
+					if err != nil {
+						return report, err
+					}
+					if inPrevious {
+						problem.Problem = CommitInPrevRelease
+						report.Problems = append(report.Problems, problem)
+					}
+				}
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// previousTag returns the tag and commit of the release before entries[i]: the
+// nearest older release of the same component whose tag can be found, so the
+// commits of releases that were never tagged count towards the next one. It
+// returns empty strings if there is none.
+func previousTag(
+	repo *git.Repository,
+	entries []ChangelogEntry,
+	i int,
+	template string,
+	component string,
+) (string, string) {
+	for j := i + 1; j < len(entries); j++ {
+		if entries[j].Component != entries[i].Component {
+			continue
+		}
+		tag := TagName(template, entries[j], component)
+		if commit := resolveTag(repo, tag); commit != "" {
+			return tag, commit
+		}
+	}
+	return "", ""
+}
+
+// resolveTag returns the commit a tag points at, or an empty string if the
+// tag does not exist.
+func resolveTag(repo *git.Repository, tag string) string {
+	commit, err := repo.ResolveCommit("refs/tags/" + tag)
+	if err != nil {
+		return ""
+	}
+	return commit
+}
+
+// sortedSections returns the entry's section names in a stable order.
+func sortedSections(entry ChangelogEntry) []string {
+	sections := make([]string, 0, len(entry.Changes))
+	for section := range entry.Changes {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+	return sections
+}
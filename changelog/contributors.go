@@ -0,0 +1,110 @@
+package changelog
+
+import (
+	"sort"
+	"strings"
+
+	"cl-parse/git"
+)
+
+// Contributor is a commit author or co-author credited in a release.
+type Contributor struct {
+	Name      string `json:"name"                yaml:"name"                toml:"name"`
+	Email     string `json:"email,omitempty"     yaml:"email,omitempty"     toml:"email,omitempty"`
+	Commits   int    `json:"commits"             yaml:"commits"             toml:"commits"`
+	FirstTime bool   `json:"firstTime,omitempty" yaml:"firstTime,omitempty" toml:"firstTime,omitempty"`
+}
+
+// AddContributors sets the contributors of the newest n releases in entries
+// to the distinct authors and co-authors of the commits between its tag and
+// the previous release's tag. Contributors without an earlier commit are
+// flagged as first-time contributors. Releases whose tags cannot be found are
+// left unchanged.
+//
+// Each component's history is walked once, from its oldest release to its
+// newest, so older releases only bound the walk.
+func AddContributors(
+	repo *git.Repository,
+	entries []ChangelogEntry,
+	n int,
+	template string,
+	component string,
+) error {
+	walkers := make(map[string]*git.HistoryWalker) // By entry component
+	seen := make(map[string]map[string]bool)       // Identities credited so far, by entry component
+
+	for i := min(n, len(entries)) - 1; i >= 0; i-- {
+		tagCommit := resolveTag(repo, TagName(template, entries[i], component))
+		if tagCommit == "" {
+			continue
+		}
+
+		key := entries[i].Component
+		walker, ok := walkers[key]
+		if !ok {
+			walker = repo.NewHistoryWalker()
+			walkers[key] = walker
+			seen[key] = make(map[string]bool)
+
+			_, prevCommit := previousTag(repo, entries, i, template, component)
+			if prevCommit != "" {
+				history, err := walker.Walk(prevCommit)
+				if err != nil {
+					return err
+				}
+				addIdentities(seen[key], history)
+			}
+		}
+
+		commits, err := walker.Walk(tagCommit)
+		if err != nil {
+			return err
+		}
+		entries[i].Contributors = contributorsOf(commits, seen[key])
+		addIdentities(seen[key], commits)
+	}
+
+	return nil
+}
+
+// addIdentities records the authors and co-authors of commits in seen.
+func addIdentities(seen map[string]bool, commits []*git.Commit) {
+	for _, commit := range commits {
+		for _, author := range commit.Authors() {
+			seen[git.IdentityKey(author.Name, author.Email)] = true
+		}
+	}
+}
+
+// contributorsOf collects the authors of commits, sorted by name.
+func contributorsOf(commits []*git.Commit, previous map[string]bool) []Contributor {
+	byKey := make(map[string]*Contributor)
+	var keys []string
+
+	for _, commit := range commits {
+		for _, author := range commit.Authors() {
+			key := git.IdentityKey(author.Name, author.Email)
+			contributor, ok := byKey[key]
+			if !ok {
+				contributor = &Contributor{
+					Name:      author.Name,
+					Email:     author.Email,
+					FirstTime: !previous[key],
+				}
+				byKey[key] = contributor
+				keys = append(keys, key)
+			}
+			contributor.Commits++
+		}
+	}
+
+	contributors := make([]Contributor, 0, len(keys))
+	for _, key := range keys {
+		contributors = append(contributors, *byKey[key])
+	}
+	sort.SliceStable(contributors, func(i, j int) bool {
+		return strings.ToLower(contributors[i].Name) < strings.ToLower(contributors[j].Name)
+	})
+
+	return contributors
+}
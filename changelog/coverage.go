@@ -0,0 +1,133 @@
+package changelog
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"cl-parse/git"
+)
+
+// DefaultCoverageTypes are the conventional commit types considered user-facing.
+var DefaultCoverageTypes = []string{"feat", "fix", "perf", "revert"}
+
+var conventionalPattern = regexp.MustCompile(`^(\w+)(?:\([^)]*\))?(!)?: `)
+
+// CoverageReport compares the commits between consecutive release tags with
+// the commits referenced by each release's changelog entry.
+type CoverageReport struct {
+	Commits    int               `json:"commits"    yaml:"commits"    toml:"commits"`
+	Covered    int               `json:"covered"    yaml:"covered"    toml:"covered"`
+	Percentage float64           `json:"percentage" yaml:"percentage" toml:"percentage"`
+	Releases   []ReleaseCoverage `json:"releases"   yaml:"releases"   toml:"releases"`
+}
+
+// ReleaseCoverage is the coverage of a single release.
+type ReleaseCoverage struct {
+	Version         string            `json:"version"         yaml:"version"         toml:"version"`
+	Tag             string            `json:"tag"             yaml:"tag"             toml:"tag"`
+	PreviousTag     string            `json:"previousTag"     yaml:"previousTag"     toml:"previousTag"`
+	Commits         int               `json:"commits"         yaml:"commits"         toml:"commits"`
+	Covered         int               `json:"covered"         yaml:"covered"         toml:"covered"`
+	Percentage      float64           `json:"percentage"      yaml:"percentage"      toml:"percentage"`
+	MissingCommits  []UncoveredCommit `json:"missingCommits"  yaml:"missingCommits"  toml:"missingCommits"`
+	UnlinkedChanges []string          `json:"unlinkedChanges" yaml:"unlinkedChanges" toml:"unlinkedChanges"`
+}
+
+// UncoveredCommit is a user-facing commit missing from the changelog.
+type UncoveredCommit struct {
+	Commit  string `json:"commit"  yaml:"commit"  toml:"commit"`
+	Subject string `json:"subject" yaml:"subject" toml:"subject"`
+}
+
+// Coverage walks the commits between each release's tag and the previous
+// release's tag and reports which user-facing commits, by conventional commit
+// type or breaking-change marker, are missing from the changelog. Releases
+// whose tags cannot be found are skipped; the oldest release covers its tag's
+// entire history.
+func Coverage(
+	repo *git.Repository,
+	entries []ChangelogEntry,
+	template string,
+	component string,
+	types []string,
+) (CoverageReport, error) {
+	report := CoverageReport{Releases: []ReleaseCoverage{}}
+
+	for i, entry := range entries {
+		tag := TagName(template, entry, component)
+		tagCommit := resolveTag(repo, tag)
+		if tagCommit == "" {
+			continue
+		}
+
+		release := ReleaseCoverage{
+			Version:         entry.Version,
+			Tag:             tag,
+			MissingCommits:  []UncoveredCommit{},
+			UnlinkedChanges: []string{},
+		}
+
+		var prevCommit string
+		release.PreviousTag, prevCommit = previousTag(repo, entries, i, template, component)
+
+		var linked []string
+		for _, section := range sortedSections(entry) {
+			for _, change := range entry.Changes[section] {
+				if change.Commit == "" {
+					release.UnlinkedChanges = append(release.UnlinkedChanges, change.Description)
+					continue
+				}
+				linked = append(linked, change.Commit)
+			}
+		}
+
+		commits, err := repo.CommitsBetween(prevCommit, tagCommit)
+		if err != nil {
+			return report, err
+		}
+
+		for _, commit := range commits {
+			if commit.Parents > 1 || !isUserFacing(commit.Subject, types) {
+				continue
+			}
+
+			release.Commits++
+			if slices.ContainsFunc(linked, func(sha string) bool {
+				return strings.HasPrefix(commit.Hash, sha)
+			}) {
+				release.Covered++
+				continue
+			}
+			release.MissingCommits = append(release.MissingCommits, UncoveredCommit{
+				Commit:  commit.Hash,
+				Subject: commit.Subject,
+			})
+		}
+
+		release.Percentage = percentage(release.Covered, release.Commits)
+		report.Commits += release.Commits
+		report.Covered += release.Covered
+		report.Releases = append(report.Releases, release)
+	}
+
+	report.Percentage = percentage(report.Covered, report.Commits)
+	return report, nil
+}
+
+// isUserFacing reports whether a commit subject has one of the given
+// conventional commit types or is marked as a breaking change.
+func isUserFacing(subject string, types []string) bool {
+	matches := conventionalPattern.FindStringSubmatch(subject)
+	if matches == nil {
+		return false
+	}
+	return matches[2] == "!" || slices.Contains(types, strings.ToLower(matches[1]))
+}
+
+func percentage(covered, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(covered) / float64(total) * 100
+}
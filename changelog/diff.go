@@ -0,0 +1,208 @@
+package changelog
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Statuses of a VersionDiff.
+const (
+	DiffAdded    = "added"
+	DiffRemoved  = "removed"
+	DiffModified = "modified"
+)
+
+// DiffReport lists the differences between two versions of a changelog.
+type DiffReport struct {
+	Versions []VersionDiff `json:"versions" yaml:"versions" toml:"versions"`
+}
+
+// VersionDiff describes how a single release changed. Any change to a release
+// that already existed in the base changelog is an error, except marking it
+// as yanked.
+type VersionDiff struct {
+	Component string       `json:"component,omitempty" yaml:"component,omitempty" toml:"component,omitempty"`
+	Version   string       `json:"version"             yaml:"version"             toml:"version"`
+	Status    string       `json:"status"              yaml:"status"              toml:"status"`
+	Error     bool         `json:"error"               yaml:"error"               toml:"error"`
+	Fields    []FieldDiff  `json:"fields,omitempty"    yaml:"fields,omitempty"    toml:"fields,omitempty"`
+	Added     []ChangeDiff `json:"added,omitempty"     yaml:"added,omitempty"     toml:"added,omitempty"`
+	Removed   []ChangeDiff `json:"removed,omitempty"   yaml:"removed,omitempty"   toml:"removed,omitempty"`
+	Modified  []ChangeDiff `json:"modified,omitempty"  yaml:"modified,omitempty"  toml:"modified,omitempty"`
+}
+
+// FieldDiff is a change to a release's heading, e.g. its date.
+type FieldDiff struct {
+	Field  string `json:"field"  yaml:"field"  toml:"field"`
+	Before string `json:"before" yaml:"before" toml:"before"`
+	After  string `json:"after"  yaml:"after"  toml:"after"`
+}
+
+// ChangeDiff is a change item that was added, removed or modified.
+type ChangeDiff struct {
+	Section string  `json:"section"          yaml:"section"          toml:"section"`
+	Before  *Change `json:"before,omitempty" yaml:"before,omitempty" toml:"before,omitempty"`
+	After   *Change `json:"after,omitempty"  yaml:"after,omitempty"  toml:"after,omitempty"`
+}
+
+// OK reports whether no released section was modified.
+func (r DiffReport) OK() bool {
+	for _, version := range r.Versions {
+		if version.Error {
+			return false
+		}
+	}
+	return true
+}
+
+// Diff compares a base changelog with a newer one. Releases are matched by
+// component and version; changes within a release by section and commit,
+// falling back to scope and description for changes without a commit.
+func Diff(base, head []ChangelogEntry) DiffReport {
+	report := DiffReport{Versions: []VersionDiff{}}
+
+	baseByKey := make(map[string]ChangelogEntry, len(base))
+	for _, entry := range base {
+		baseByKey[entryKey(entry)] = entry
+	}
+	headKeys := make(map[string]bool, len(head))
+
+	for _, entry := range head {
+		headKeys[entryKey(entry)] = true
+
+		old, released := baseByKey[entryKey(entry)]
+		if !released {
+			diff := newVersionDiff(entry, DiffAdded)
+			diff.Added = changeDiffs(entry, func(c Change) ChangeDiff { return ChangeDiff{After: &c} })
+			report.Versions = append(report.Versions, diff)
+			continue
+		}
+
+		diff := diffEntries(old, entry)
+		if diff.Fields != nil || diff.Added != nil || diff.Removed != nil || diff.Modified != nil {
+			report.Versions = append(report.Versions, diff)
+		}
+	}
+
+	for _, entry := range base {
+		if headKeys[entryKey(entry)] {
+			continue
+		}
+		diff := newVersionDiff(entry, DiffRemoved)
+		diff.Error = true
+		diff.Removed = changeDiffs(entry, func(c Change) ChangeDiff { return ChangeDiff{Before: &c} })
+		report.Versions = append(report.Versions, diff)
+	}
+
+	return report
+}
+
+func diffEntries(old, entry ChangelogEntry) VersionDiff {
+	diff := newVersionDiff(entry, DiffModified)
+
+	fields := []FieldDiff{
+		{"date", formatDate(old), formatDate(entry)},
+		{"compareUrl", old.CompareURL, entry.CompareURL},
+		{"yanked", fmt.Sprint(old.Yanked), fmt.Sprint(entry.Yanked)},
+		{"yankedReason", old.YankedReason, entry.YankedReason},
+	}
+	for _, field := range fields {
+		if field.Before == field.After {
+			continue
+		}
+		diff.Fields = append(diff.Fields, field)
+		if field.Field != "yanked" && field.Field != "yankedReason" {
+			diff.Error = true
+		}
+	}
+
+	sections := sortedSections(old)
+	for _, section := range sortedSections(entry) {
+		if !slices.Contains(sections, section) {
+			sections = append(sections, section)
+		}
+	}
+	sort.Strings(sections)
+
+	for _, section := range sections {
+		before := old.Changes[section]
+		after := entry.Changes[section]
+		matched := make([]bool, len(after))
+
+		for _, b := range before {
+			j := matchChange(b, after, matched)
+			if j == -1 {
+				diff.Removed = append(diff.Removed, ChangeDiff{Section: section, Before: &b})
+				continue
+			}
+			matched[j] = true
+			if !sameChange(b, after[j]) {
+				a := after[j]
+				diff.Modified = append(diff.Modified, ChangeDiff{Section: section, Before: &b, After: &a})
+			}
+		}
+
+		for j, a := range after {
+			if !matched[j] {
+				diff.Added = append(diff.Added, ChangeDiff{Section: section, After: &a})
+			}
+		}
+	}
+
+	if diff.Added != nil || diff.Removed != nil || diff.Modified != nil {
+		diff.Error = true
+	}
+	return diff
+}
+
+func newVersionDiff(entry ChangelogEntry, status string) VersionDiff {
+	return VersionDiff{Component: entry.Component, Version: entry.Version, Status: status}
+}
+
+// changeDiffs converts every change in an entry, ordered by section.
+func changeDiffs(entry ChangelogEntry, convert func(Change) ChangeDiff) []ChangeDiff {
+	var diffs []ChangeDiff
+	for _, section := range sortedSections(entry) {
+		for _, change := range entry.Changes[section] {
+			diff := convert(change)
+			diff.Section = section
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs
+}
+
+// matchChange returns the index of the first unmatched change in candidates
+// with the same identity as change, or -1.
+func matchChange(change Change, candidates []Change, matched []bool) int {
+	for j, candidate := range candidates {
+		if !matched[j] && changeKey(candidate) == changeKey(change) {
+			return j
+		}
+	}
+	return -1
+}
+
+func changeKey(change Change) string {
+	if change.Commit != "" {
+		return "commit:" + change.Commit
+	}
+	return "text:" + change.Scope + "\x00" + change.Description
+}
+
+func sameChange(a, b Change) bool {
+	return a.Scope == b.Scope && a.Description == b.Description && a.Commit == b.Commit
+}
+
+func entryKey(entry ChangelogEntry) string {
+	return entry.Component + "\x00" + entry.Version
+}
+
+func formatDate(entry ChangelogEntry) string {
+	if entry.Undated {
+		return ""
+	}
+	return entry.Date.Format(time.RFC3339)
+}
@@ -0,0 +1,109 @@
+package changelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	manifestConfigFile = "release-please-config.json"
+	manifestFile       = ".release-please-manifest.json"
+	defaultChangelog   = "CHANGELOG.md"
+)
+
+// Package is a single release-please package and its parsed changelog.
+type Package struct {
+	Path            string           `json:"path"                yaml:"path"                toml:"path"`
+	Component       string           `json:"component,omitempty" yaml:"component,omitempty" toml:"component,omitempty"`
+	ChangelogPath   string           `json:"changelogPath"       yaml:"changelogPath"       toml:"changelogPath"`
+	ManifestVersion string           `json:"manifestVersion"     yaml:"manifestVersion"     toml:"manifestVersion"`
+	LatestVersion   string           `json:"latestVersion"       yaml:"latestVersion"       toml:"latestVersion"`
+	InSync          bool             `json:"inSync"              yaml:"inSync"              toml:"inSync"`
+	Entries         []ChangelogEntry `json:"entries"             yaml:"entries"             toml:"entries"`
+}
+
+type manifestConfig struct {
+	ChangelogPath string                           `json:"changelog-path"`
+	Packages      map[string]manifestPackageConfig `json:"packages"`
+}
+
+type manifestPackageConfig struct {
+	Component     string `json:"component"`
+	PackageName   string `json:"package-name"`
+	ChangelogPath string `json:"changelog-path"`
+}
+
+// LoadPackages reads the release-please config and manifest from root and
+// returns every configured package, sorted by path. Entries are left empty.
+func LoadPackages(root string) ([]Package, error) {
+	var config manifestConfig
+	if err := readJSON(filepath.Join(root, manifestConfigFile), &config); err != nil {
+		return nil, err
+	}
+
+	var versions map[string]string
+	if err := readJSON(filepath.Join(root, manifestFile), &versions); err != nil {
+		return nil, err
+	}
+
+	packages := make([]Package, 0, len(config.Packages))
+	for pkgPath, pkgConfig := range config.Packages {
+		changelogPath := pkgConfig.ChangelogPath
+		if changelogPath == "" {
+			changelogPath = config.ChangelogPath
+		}
+		if changelogPath == "" {
+			changelogPath = defaultChangelog
+		}
+
+		component := pkgConfig.Component
+		if component == "" {
+			component = path.Base(pkgConfig.PackageName)
+		}
+		if component == "." && pkgPath != "." {
+			component = path.Base(pkgPath)
+		}
+		if component == "." {
+			component = ""
+		}
+
+		packages = append(packages, Package{
+			Path:            pkgPath,
+			Component:       component,
+			ChangelogPath:   filepath.Join(root, filepath.FromSlash(pkgPath), changelogPath),
+			ManifestVersion: versions[pkgPath],
+		})
+	}
+
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Path < packages[j].Path
+	})
+
+	return packages, nil
+}
+
+// SetEntries attaches parsed entries to the package and cross-checks the
+// latest entry against the version recorded in the manifest.
+func (pkg *Package) SetEntries(entries []ChangelogEntry) {
+	pkg.Entries = entries
+	pkg.LatestVersion = ""
+	if len(entries) > 0 {
+		pkg.LatestVersion = entries[0].Version
+	}
+	pkg.InSync = pkg.LatestVersion == pkg.ManifestVersion
+}
+
+func readJSON(path string, v any) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package changelog
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"cl-parse/origin"
+)
+
+// DefaultWorkers is the number of related items fetched concurrently when
+// Parser.Workers is unset.
+const DefaultWorkers = 8
+
+// originConfig returns the configuration used to fetch related items.
+func (p *Parser) originConfig() origin.Config {
+	return origin.Config{
+		URL:           p.originUrl,
+		Token:         p.OriginToken,
+		Hosts:         p.OriginHosts,
+		Probe:         p.OriginProbe,
+		DiscoverToken: p.OriginDiscoverToken,
+		GitCredential: p.OriginGitCredential,
+		GitHubApp:     p.GitHubApp,
+		HTTPClient:    p.HTTPClient,
+		Cache:         p.Cache,
+	}
+}
+
+// fetchRelatedItems fetches the details of every related item in the parsed
+// entries. Each distinct token is fetched once, by a pool of workers, and the
+// results are attached in document order, each change getting its own copy.
+// Items the provider cannot find are replaced with nil. No more items are
+// fetched once one fails.
+func (p *Parser) fetchRelatedItems() error {
+	tokens := p.relatedTokens()
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	provider, err := origin.NewIssueProvider(p.originConfig())
+	if err != nil {
+		return fmt.Errorf("failed to create issue provider: %w", err)
+	}
+
+	workers := p.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	workers = min(workers, len(tokens))
+
+	issues := make([]*origin.Issue, len(tokens))
+	errs := make([]error, len(tokens))
+	jobs := make(chan int)
+	failed := make(chan struct{}) // Closed when a fetch fails
+	var failOnce sync.Once
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				select {
+				case <-failed:
+					continue
+				default:
+				}
+				issues[i], errs[i] = provider.GetIssue(tokens[i])
+				if errs[i] != nil {
+					failOnce.Do(func() { close(failed) })
+				}
+			}
+		}()
+	}
+dispatch:
+	for i := range tokens {
+		select {
+		case jobs <- i:
+		case <-failed:
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	fetched := make(map[string]*origin.Issue, len(tokens))
+	for i, token := range tokens {
+		if errs[i] != nil {
+			return fmt.Errorf("failed to get related item details for %s: %w", token, errs[i])
+		}
+		if issues[i] != nil {
+			issues[i].Number = token // preserve original token with prefix
+		}
+		fetched[token] = issues[i]
+	}
+
+	for _, entry := range p.entries {
+		for _, changes := range entry.Changes {
+			for _, change := range changes {
+				for j, item := range change.RelatedItems {
+					if item != nil {
+						change.RelatedItems[j] = cloneIssue(fetched[item.Number])
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// cloneIssue returns a copy of issue that shares no memory with it, so
+// changes referencing the same item can be modified independently.
+func cloneIssue(issue *origin.Issue) *origin.Issue {
+	if issue == nil {
+		return nil
+	}
+	clone := *issue
+	clone.Labels = slices.Clone(issue.Labels)
+	clone.Assignees = slices.Clone(issue.Assignees)
+	clone.CreatedAt = cloneTime(issue.CreatedAt)
+	clone.ClosedAt = cloneTime(issue.ClosedAt)
+	clone.MergedAt = cloneTime(issue.MergedAt)
+	return &clone
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	clone := *t
+	return &clone
+}
+
+// relatedTokens returns the distinct related item tokens in the parsed
+// entries, in the order they first appear.
+func (p *Parser) relatedTokens() []string {
+	seen := make(map[string]bool)
+	var tokens []string
+
+	for _, entry := range p.entries {
+		for _, section := range sortedSections(entry) {
+			for _, change := range entry.Changes[section] {
+				for _, item := range change.RelatedItems {
+					if item != nil && !seen[item.Number] {
+						seen[item.Number] = true
+						tokens = append(tokens, item.Number)
+					}
+				}
+			}
+		}
+	}
+
+	return tokens
+}
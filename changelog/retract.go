@@ -0,0 +1,48 @@
+package changelog
+
+import (
+	"fmt"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// ApplyRetractions marks entries covered by a retract directive in the given
+// go.mod content as yanked, using the directive's rationale as the reason.
+func ApplyRetractions(entries []ChangelogEntry, gomod []byte) error {
+	file, err := modfile.ParseLax("go.mod", gomod, nil)
+	if err != nil {
+		return fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+
+	for i := range entries {
+		version := "v" + entries[i].Version
+		if !semver.IsValid(version) {
+			continue
+		}
+
+		for _, retract := range file.Retract {
+			if semver.Compare(version, retract.Low) < 0 || semver.Compare(version, retract.High) > 0 {
+				continue
+			}
+			entries[i].Yanked = true
+			if entries[i].YankedReason == "" {
+				entries[i].YankedReason = retract.Rationale
+			}
+			break
+		}
+	}
+
+	return nil
+}
+
+// ExcludeYanked returns the entries that have not been yanked.
+func ExcludeYanked(entries []ChangelogEntry) []ChangelogEntry {
+	var filtered []ChangelogEntry
+	for _, entry := range entries {
+		if !entry.Yanked {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
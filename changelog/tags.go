@@ -0,0 +1,135 @@
+package changelog
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"cl-parse/git"
+)
+
+// DefaultTagTemplate is the tag name release-please and semantic-release use
+// for single-package repositories.
+const DefaultTagTemplate = "v{version}"
+
+const (
+	tagVersionPattern   = `[0-9]+(?:\.[0-9]+)*(?:-[0-9A-Za-z.-]+)?`
+	tagComponentPattern = `[0-9A-Za-z@._/-]+?`
+)
+
+// TagReport lists the differences between a changelog and the repository's tags.
+type TagReport struct {
+	MissingEntries []string       `json:"missingEntries" yaml:"missingEntries" toml:"missingEntries"`
+	MissingTags    []string       `json:"missingTags"    yaml:"missingTags"    toml:"missingTags"`
+	DateMismatches []DateMismatch `json:"dateMismatches" yaml:"dateMismatches" toml:"dateMismatches"`
+}
+
+// DateMismatch is a release whose heading date disagrees with its tag.
+type DateMismatch struct {
+	Version   string    `json:"version"   yaml:"version"   toml:"version"`
+	Tag       string    `json:"tag"       yaml:"tag"       toml:"tag"`
+	EntryDate time.Time `json:"entryDate" yaml:"entryDate" toml:"entryDate"`
+	TagDate   time.Time `json:"tagDate"   yaml:"tagDate"   toml:"tagDate"`
+}
+
+// OK reports whether the changelog and tags agree.
+func (r TagReport) OK() bool {
+	return len(r.MissingEntries) == 0 && len(r.MissingTags) == 0 && len(r.DateMismatches) == 0
+}
+
+// TagName renders the tag name for an entry from a template such as
+// "v{version}" or "{component}-v{version}". component is used for entries
+// whose heading did not name one.
+func TagName(template string, entry ChangelogEntry, component string) string {
+	if entry.Component != "" {
+		component = entry.Component
+	}
+	return strings.NewReplacer("{version}", entry.Version, "{component}", component).
+		Replace(template)
+}
+
+// tagRegex builds a pattern matching every tag name the template can produce.
+// When component is set, only that component's tags match.
+func tagRegex(template string, component string) *regexp.Regexp {
+	componentPattern := tagComponentPattern
+	if component != "" {
+		componentPattern = regexp.QuoteMeta(component)
+	}
+
+	pattern := strings.NewReplacer(
+		regexp.QuoteMeta("{version}"), tagVersionPattern,
+		regexp.QuoteMeta("{component}"), componentPattern,
+	).Replace(regexp.QuoteMeta(template))
+
+	return regexp.MustCompile("^" + pattern + "$")
+}
+
+// VerifyTags compares changelog entries against the repository's tags. Tags
+// matching the template without a changelog entry, entries without a tag, and
+// heading dates more than toleranceDays away from the tag date are reported.
+func VerifyTags(
+	entries []ChangelogEntry,
+	tags []git.Tag,
+	template string,
+	component string,
+	toleranceDays int,
+) TagReport {
+	report := TagReport{
+		MissingEntries: []string{},
+		MissingTags:    []string{},
+		DateMismatches: []DateMismatch{},
+	}
+
+	tagsByName := make(map[string]git.Tag, len(tags))
+	for _, tag := range tags {
+		tagsByName[tag.Name] = tag
+	}
+
+	expected := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		name := TagName(template, entry, component)
+		expected[name] = true
+
+		tag, ok := tagsByName[name]
+		if !ok {
+			report.MissingTags = append(report.MissingTags, name)
+			continue
+		}
+
+		if entry.Undated {
+			continue
+		}
+		if daysBetween(entry.Date, tag.Date) > toleranceDays {
+			report.DateMismatches = append(report.DateMismatches, DateMismatch{
+				Version:   entry.Version,
+				Tag:       tag.Name,
+				EntryDate: entry.Date,
+				TagDate:   tag.Date,
+			})
+		}
+	}
+
+	matcher := tagRegex(template, component)
+	for _, tag := range tags {
+		if matcher.MatchString(tag.Name) && !expected[tag.Name] {
+			report.MissingEntries = append(report.MissingEntries, tag.Name)
+		}
+	}
+	sort.Strings(report.MissingEntries)
+
+	return report
+}
+
+// daysBetween returns the number of calendar days between two dates, each
+// taken in its own time zone.
+func daysBetween(a, b time.Time) int {
+	dayA := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	dayB := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+
+	days := int(dayA.Sub(dayB).Hours() / 24)
+	if days < 0 {
+		return -days
+	}
+	return days
+}
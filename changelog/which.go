@@ -0,0 +1,138 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"cl-parse/git"
+)
+
+// Sources of a WhichResult.
+const (
+	SourceChangelog = "changelog" // the changelog references the commit or issue directly
+	SourceGit       = "git"       // a commit in the release's tag range matches
+)
+
+var issueTokenPattern = regexp.MustCompile(`^[#!]\d+$`)
+
+// WhichResult is the earliest release that shipped a commit or issue.
+type WhichResult struct {
+	Query     string    `json:"query"               yaml:"query"               toml:"query"`
+	Component string    `json:"component,omitempty" yaml:"component,omitempty" toml:"component,omitempty"`
+	Version   string    `json:"version"             yaml:"version"             toml:"version"`
+	Date      time.Time `json:"date"                yaml:"date"                toml:"date"`
+	Source    string    `json:"source"              yaml:"source"              toml:"source"`
+	Commit    string    `json:"commit,omitempty"    yaml:"commit,omitempty"    toml:"commit,omitempty"`
+	Change    *Change   `json:"change,omitempty"    yaml:"change,omitempty"    toml:"change,omitempty"`
+}
+
+// IsIssueToken reports whether query is an issue or pull request reference
+// such as "#123" or "!42" rather than a commit SHA.
+func IsIssueToken(query string) bool {
+	return issueTokenPattern.MatchString(query)
+}
+
+// FindInChangelog returns the earliest release whose changes mention the
+// query, either as a (possibly abbreviated) commit SHA or as a related item.
+// It returns nil when no entry references it.
+func FindInChangelog(entries []ChangelogEntry, query string) *WhichResult {
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	// entries are newest first, so walk backwards to find the earliest release
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		for _, section := range sortedSections(entry) {
+			for _, change := range entry.Changes[section] {
+				if !changeMentions(change, query) {
+					continue
+				}
+				return &WhichResult{
+					Query:     query,
+					Component: entry.Component,
+					Version:   entry.Version,
+					Date:      entry.Date,
+					Source:    SourceChangelog,
+					Commit:    change.Commit,
+					Change:    &change,
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// FindInHistory returns the earliest release whose tag range contains a
+// commit matching the query: the commit itself for a SHA, or a commit whose
+// message mentions the issue token. Releases without tags are skipped.
+func FindInHistory(
+	repo *git.Repository,
+	entries []ChangelogEntry,
+	template string,
+	component string,
+	query string,
+) (*WhichResult, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	var mention *regexp.Regexp
+	sha := ""
+	if IsIssueToken(query) {
+		mention = regexp.MustCompile(`(?:^|[^\w])` + regexp.QuoteMeta(query) + `\b`)
+	} else {
+		resolved, err := repo.ResolveCommit(query)
+		if err != nil {
+			return nil, err
+		}
+		sha = resolved
+	}
+
+	prevCommit := ""
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		tagCommit := resolveTag(repo, TagName(template, entry, component))
+		if tagCommit == "" {
+			continue
+		}
+
+		commits, err := repo.CommitsBetween(prevCommit, tagCommit)
+		if err != nil {
+			return nil, err
+		}
+		prevCommit = tagCommit
+
+		for _, commit := range commits {
+			if (mention != nil && mention.MatchString(commit.Message)) || commit.Hash == sha {
+				return &WhichResult{
+					Query:     query,
+					Component: entry.Component,
+					Version:   entry.Version,
+					Date:      entry.Date,
+					Source:    SourceGit,
+					Commit:    commit.Hash,
+				}, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no release found for %s", query)
+}
+
+// changeMentions reports whether a change references the lowercased query.
+func changeMentions(change Change, query string) bool {
+	if IsIssueToken(query) {
+		for _, item := range change.RelatedItems {
+			if item != nil && item.Number == query {
+				return true
+			}
+		}
+		return false
+	}
+
+	commit := strings.ToLower(change.Commit)
+	if commit == "" || len(query) < 4 {
+		return false
+	}
+	return strings.HasPrefix(commit, query) || strings.HasPrefix(query, commit)
+}
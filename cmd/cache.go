@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"cl-parse/origin"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the on-disk cache of fetched related items",
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove cached related items older than --max-age, or all of them with --all",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		maxAge, _ := cmd.Flags().GetDuration("max-age")
+		all, _ := cmd.Flags().GetBool("all")
+		if all {
+			maxAge = 0
+		}
+
+		dir, err := origin.DefaultCacheDir()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		removed, err := origin.NewCache(dir, origin.DefaultCacheTTL).Prune(maxAge)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Printf("removed %d cached items from %s\n", removed, dir)
+	},
+}
+
+func init() {
+	cachePruneCmd.Flags().
+		Duration("max-age", origin.DefaultCacheTTL, "remove items cached longer ago than this")
+	cachePruneCmd.Flags().Bool("all", false, "remove every cached item")
+
+	cacheCmd.AddCommand(cachePruneCmd)
+	cmd.AddCommand(cacheCmd)
+}
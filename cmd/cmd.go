@@ -3,7 +3,9 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 
 	"cl-parse/changelog"
 	"cl-parse/git"
+	"cl-parse/origin"
 )
 
 const VERSION = "0.7.0" // x-release-please-version
@@ -25,19 +28,41 @@ type options struct {
 	sinceDays        int
 	includeBody      bool
 	fetchItemDetails bool
+	commitDetails    bool
 	token            string
+	tokenEnv         string
+	noDiscovery      bool
+	gitCredential    bool
+	githubAppID      string
+	githubAppKey     string
+	githubApp        *origin.GitHubApp
 	format           string
+	manifest         bool
+	component        string
+	headingLevel     int
+	dateLayouts      []string
+	excludeYanked    bool
+	retractions      bool
+	goMod            string
+	repo             string
+	tagTemplate      string
+	rev              string
+	contributors     bool
+	hosts            map[string]string
+	originHosts      map[string]origin.HostConfig
+	probe            bool
+	workers          int
+	verbose          bool
+	httpClient       *http.Client
+	noCache          bool
+	refresh          bool
+	cacheTTL         time.Duration
 }
 
 var cmd = &cobra.Command{
 	Use:  "cl-parse [flags] [path]",
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		changelogPath := "./CHANGELOG.md"
-		if len(args) > 0 {
-			changelogPath = args[0]
-		}
-
 		opts := getOptions(cmd)
 
 		if opts.version {
@@ -45,50 +70,95 @@ var cmd = &cobra.Command{
 			os.Exit(0)
 		}
 
-		content, err := os.ReadFile(changelogPath)
-		if err != nil {
+		if err := validateScopeOptions(opts); err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
 
-		parser := changelog.NewParser()
-		parser.IncludeBody = opts.includeBody
-		parser.FetchItemDetails = opts.fetchItemDetails
-		parser.OriginToken = opts.token
-
-		if (parser.IncludeBody || parser.FetchItemDetails) && !git.IsGitRepo(".") {
-			fmt.Println("Cannot fetch commits: Not a git repository")
+		if err := run(opts, args); err != nil {
+			fmt.Println(err)
 			os.Exit(1)
 		}
+	},
+}
 
-		entries, err := parser.Parse(string(content))
+// run parses the changelog and prints the requested releases. Errors are
+// returned rather than exiting so deferred API statistics are still logged.
+func run(opts options, args []string) error {
+	if opts.fetchItemDetails {
+		originHosts, err := loadHosts(opts.hosts)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			return err
 		}
+		opts.originHosts = originHosts
 
-		if err := validateScopeOptions(opts); err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+		if opts.token == "" && opts.tokenEnv != "" {
+			opts.token = os.Getenv(opts.tokenEnv)
+			if opts.token == "" {
+				return fmt.Errorf("environment variable %s is not set", opts.tokenEnv)
+			}
 		}
 
-		filtered := filterEntries(entries, opts.last, opts.sinceDays, time.Now().UTC())
+		if opts.githubAppID != "" {
+			app, err := origin.LoadGitHubApp(opts.githubAppID, opts.githubAppKey)
+			if err != nil {
+				return err
+			}
+			opts.githubApp = app
+		}
 
-		var outputErr error
-		switch {
-		case opts.latest:
-			outputErr = handleLatest(filtered, opts.format)
-		case opts.release != "":
-			outputErr = handleRelease(filtered, opts.release, opts.format)
-		default:
-			outputErr = outputFormatted(filtered, opts.format)
+		if opts.verbose {
+			transport := origin.NewRetryTransport(nil)
+			transport.Logf = logf
+			opts.httpClient = &http.Client{Transport: transport}
+			defer logStats(transport)
 		}
+	}
 
-		if outputErr != nil {
-			fmt.Println(outputErr)
-			os.Exit(1)
+	if opts.manifest {
+		root := "."
+		if len(args) > 0 {
+			root = args[0]
 		}
-	},
+		repo, err := openRepo(opts, root)
+		if err != nil {
+			return err
+		}
+		return handleManifest(root, opts, repo)
+	}
+
+	changelogPath := changelogPathFromArgs(args)
+
+	repo, err := openRepo(opts, changelogPath)
+	if err != nil {
+		return err
+	}
+
+	entries, err := readChangelog(changelogPath, opts, repo)
+	if err != nil {
+		return err
+	}
+
+	entries = changelog.FilterComponent(entries, opts.component)
+	if opts.contributors {
+		if err := addContributors(repo, entries, opts, opts.component); err != nil {
+			return err
+		}
+	}
+	if opts.excludeYanked {
+		entries = changelog.ExcludeYanked(entries)
+	}
+
+	filtered := filterEntries(entries, opts.last, opts.sinceDays, time.Now().UTC())
+
+	switch {
+	case opts.latest:
+		return handleLatest(filtered, opts.format)
+	case opts.release != "":
+		return handleRelease(filtered, opts.release, opts.format)
+	default:
+		return outputFormatted(filtered, opts.format)
+	}
 }
 
 func Execute() {
@@ -98,16 +168,63 @@ func Execute() {
 }
 
 func init() {
+	cmd.PersistentFlags().StringP("format", "f", "json", "output format (json, yaml, or toml)")
+	cmd.PersistentFlags().
+		String("repo", "", "path to the git repository (defaults to the one containing the changelog)")
+	cmd.PersistentFlags().
+		Int("heading-level", 0, "markdown heading level of release headings (0 to auto-detect # and ##)")
+	cmd.PersistentFlags().
+		StringSlice("date-layouts", nil, "additional Go time layouts for release dates (e.g. 02.01.2006)")
+	cmd.PersistentFlags().
+		String("component", "", "limit output to releases of a single component (e.g. my-pkg)")
+	cmd.PersistentFlags().
+		String("tag-template", changelog.DefaultTagTemplate, "tag name template (e.g. v{version} or {component}-v{version})")
+
+	cmd.PersistentFlags().
+		String("rev", "", "read the changelog as it existed at a git revision (tag, branch or commit)")
+
 	cmd.Flags().BoolP("version", "v", false, "display the current version of cl-parse")
 	cmd.Flags().BoolP("latest", "l", false, "display the most recent version from the changelog")
 	cmd.Flags().StringP("release", "r", "", "display the changelog entry for a specific release")
 	cmd.Flags().Bool("include-body", false, "include the full commit body in changelog entry")
+	cmd.Flags().
+		Bool("include-commit-details", false, "include author, committer and signature details for each commit")
 	cmd.Flags().
 		Bool("fetch-item-details", false, "fetch details for related items (e.g. GitHub issues & PRs)")
 	cmd.Flags().String("token", "", "token for fetching related items")
-	cmd.Flags().StringP("format", "f", "json", "output format (json, yaml, or toml)")
+	cmd.Flags().
+		String("token-env", "", "environment variable holding the token for fetching related items")
+	cmd.Flags().
+		Bool("no-token-discovery", false, "don't look for a token in provider environment variables or ~/.netrc")
+	cmd.Flags().
+		Bool("git-credential", false, "also ask git credential helpers for a token when none is found")
+	cmd.Flags().
+		String("github-app-id", "", "authenticate to GitHub as an installation of this GitHub App")
+	cmd.Flags().
+		String("github-app-key", "", "path to the GitHub App's private key (PEM)")
+	cmd.Flags().
+		StringToString("host", nil, "provider for a self-hosted git host as kind[:api-url] (e.g. git.example.com=forgejo)")
+	cmd.Flags().
+		Bool("probe", false, "detect the provider of unknown git hosts by querying their APIs")
+	cmd.Flags().
+		Int("workers", changelog.DefaultWorkers, "number of related items to fetch concurrently")
+	cmd.Flags().
+		Bool("verbose", false, "log retries and report API requests and rate limits to stderr")
+	cmd.Flags().Bool("no-cache", false, "don't read or write the on-disk cache of fetched related items")
+	cmd.Flags().Bool("refresh", false, "revalidate every cached related item with the API")
+	cmd.Flags().
+		Duration("cache-ttl", origin.DefaultCacheTTL, "how long cached related items are used without revalidation")
 	cmd.Flags().Int("last", 0, "limit output to the N most recent releases")
 	cmd.Flags().Int("since-days", 0, "limit output to releases within the last N days (from today, UTC)")
+	cmd.Flags().
+		Bool("manifest", false, "parse every package listed in the release-please config under [path]")
+	cmd.Flags().Bool("exclude-yanked", false, "omit releases marked as yanked or retracted")
+	cmd.Flags().
+		Bool("retractions", false, "mark releases retracted in the module's go.mod as yanked")
+	cmd.Flags().
+		String("go-mod", "", "go.mod to read retractions from (default: go.mod at the repository root)")
+	cmd.Flags().
+		Bool("contributors", false, "list the commit authors and co-authors of each release from git history")
 }
 
 func getOptions(cmd *cobra.Command) options {
@@ -118,8 +235,32 @@ func getOptions(cmd *cobra.Command) options {
 	sinceDays, _ := cmd.Flags().GetInt("since-days")
 	includeBody, _ := cmd.Flags().GetBool("include-body")
 	fetchItemDetails, _ := cmd.Flags().GetBool("fetch-item-details")
+	commitDetails, _ := cmd.Flags().GetBool("include-commit-details")
 	token, _ := cmd.Flags().GetString("token")
+	tokenEnv, _ := cmd.Flags().GetString("token-env")
+	noDiscovery, _ := cmd.Flags().GetBool("no-token-discovery")
+	gitCredential, _ := cmd.Flags().GetBool("git-credential")
+	githubAppID, _ := cmd.Flags().GetString("github-app-id")
+	githubAppKey, _ := cmd.Flags().GetString("github-app-key")
 	format, _ := cmd.Flags().GetString("format")
+	manifest, _ := cmd.Flags().GetBool("manifest")
+	component, _ := cmd.Flags().GetString("component")
+	headingLevel, _ := cmd.Flags().GetInt("heading-level")
+	dateLayouts, _ := cmd.Flags().GetStringSlice("date-layouts")
+	excludeYanked, _ := cmd.Flags().GetBool("exclude-yanked")
+	retractions, _ := cmd.Flags().GetBool("retractions")
+	goMod, _ := cmd.Flags().GetString("go-mod")
+	repo, _ := cmd.Flags().GetString("repo")
+	tagTemplate, _ := cmd.Flags().GetString("tag-template")
+	rev, _ := cmd.Flags().GetString("rev")
+	contributors, _ := cmd.Flags().GetBool("contributors")
+	hosts, _ := cmd.Flags().GetStringToString("host")
+	probe, _ := cmd.Flags().GetBool("probe")
+	workers, _ := cmd.Flags().GetInt("workers")
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	noCache, _ := cmd.Flags().GetBool("no-cache")
+	refresh, _ := cmd.Flags().GetBool("refresh")
+	cacheTTL, _ := cmd.Flags().GetDuration("cache-ttl")
 
 	return options{
 		version:          version,
@@ -129,9 +270,214 @@ func getOptions(cmd *cobra.Command) options {
 		sinceDays:        sinceDays,
 		includeBody:      includeBody,
 		fetchItemDetails: fetchItemDetails,
+		commitDetails:    commitDetails,
 		token:            token,
+		tokenEnv:         tokenEnv,
+		noDiscovery:      noDiscovery,
+		gitCredential:    gitCredential,
+		githubAppID:      githubAppID,
+		githubAppKey:     githubAppKey,
 		format:           format,
+		manifest:         manifest,
+		component:        component,
+		headingLevel:     headingLevel,
+		dateLayouts:      dateLayouts,
+		excludeYanked:    excludeYanked,
+		retractions:      retractions,
+		goMod:            goMod,
+		repo:             repo,
+		tagTemplate:      tagTemplate,
+		rev:              rev,
+		contributors:     contributors,
+		hosts:            hosts,
+		probe:            probe,
+		workers:          workers,
+		verbose:          verbose,
+		noCache:          noCache,
+		refresh:          refresh,
+		cacheTTL:         cacheTTL,
+	}
+}
+
+func changelogPathFromArgs(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return "./CHANGELOG.md"
+}
+
+// openRepo opens the repository for the changelog at path when parsing needs
+// git access, returning nil otherwise.
+func openRepo(opts options, path string) (*git.Repository, error) {
+	if !opts.includeBody && !opts.fetchItemDetails && !opts.commitDetails && !opts.contributors {
+		return nil, nil
+	}
+	return openRepoAt(opts, path)
+}
+
+// openRepoAt opens the repository given by --repo, or the repository enclosing
+// path when the flag is unset.
+func openRepoAt(opts options, path string) (*git.Repository, error) {
+	repoPath := opts.repo
+	if repoPath == "" {
+		repoPath = path
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			repoPath = filepath.Dir(path)
+		}
+	}
+
+	repo, err := git.Open(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot fetch commits: Not a git repository")
+	}
+	return repo, nil
+}
+
+func newParser(opts options, repo *git.Repository) *changelog.Parser {
+	parser := changelog.NewParser()
+	parser.Repo = repo
+	parser.IncludeBody = opts.includeBody
+	parser.FetchItemDetails = opts.fetchItemDetails
+	parser.IncludeCommitDetails = opts.commitDetails
+	parser.OriginToken = opts.token
+	parser.OriginHosts = opts.originHosts
+	parser.OriginProbe = opts.probe
+	parser.OriginDiscoverToken = !opts.noDiscovery
+	parser.OriginGitCredential = opts.gitCredential
+	parser.GitHubApp = opts.githubApp
+	parser.Workers = opts.workers
+	parser.HTTPClient = opts.httpClient
+	if !opts.noCache {
+		if dir, err := origin.DefaultCacheDir(); err == nil {
+			parser.Cache = origin.NewCache(dir, opts.cacheTTL)
+			parser.Cache.Refresh = opts.refresh
+		}
+	}
+	parser.HeadingLevel = opts.headingLevel
+	parser.DateLayouts = opts.dateLayouts
+	return parser
+}
+
+func parseChangelog(
+	path string,
+	opts options,
+	repo *git.Repository,
+) ([]changelog.ChangelogEntry, error) {
+	entries, err := readChangelog(path, opts, repo)
+	if err != nil {
+		return nil, err
+	}
+	if opts.excludeYanked {
+		entries = changelog.ExcludeYanked(entries)
+	}
+	return entries, nil
+}
+
+// readChangelog parses the changelog at path and applies --retractions,
+// keeping yanked releases.
+func readChangelog(
+	path string,
+	opts options,
+	repo *git.Repository,
+) ([]changelog.ChangelogEntry, error) {
+	content, err := readFile(path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := newParser(opts, repo).Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.retractions {
+		gomod, err := readFile(goModPath(path, opts, repo), opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read go.mod: %w", err)
+		}
+		if err := changelog.ApplyRetractions(entries, gomod); err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
+
+// addContributors credits contributors to the releases that will be output.
+// It runs before yanked releases are excluded, so their commits aren't
+// credited to the next release.
+func addContributors(repo *git.Repository, entries []changelog.ChangelogEntry, opts options, component string) error {
+	n := shownDepth(entries, opts, time.Now().UTC())
+	return changelog.AddContributors(repo, entries, n, opts.tagTemplate, component)
+}
+
+// shownDepth returns how many of the newest entries the output is drawn from,
+// applying the same selection as the output does.
+func shownDepth(entries []changelog.ChangelogEntry, opts options, now time.Time) int {
+	shown := entries
+	if opts.excludeYanked {
+		shown = changelog.ExcludeYanked(shown)
+	}
+	shown = filterEntries(shown, opts.last, opts.sinceDays, now)
+
+	var oldest *changelog.ChangelogEntry
+	switch {
+	case opts.latest:
+		oldest = latestEntry(shown)
+	case opts.release != "":
+		for i := range shown {
+			if shown[i].Version == opts.release {
+				oldest = &shown[i]
+				break
+			}
+		}
+	case len(shown) > 0:
+		oldest = &shown[len(shown)-1]
 	}
+	if oldest == nil {
+		return 0
+	}
+
+	for i := range entries {
+		if entries[i].Version == oldest.Version && entries[i].Component == oldest.Component {
+			return i + 1
+		}
+	}
+	return len(entries)
+}
+
+// goModPath returns the go.mod read by --retractions: --go-mod when set,
+// otherwise the one at the repository root, or beside the changelog when it
+// isn't in a repository.
+func goModPath(path string, opts options, repo *git.Repository) string {
+	if opts.goMod != "" {
+		return opts.goMod
+	}
+	if repo == nil {
+		repo, _ = openRepoAt(opts, path)
+	}
+	if repo != nil {
+		return filepath.Join(repo.Root(), "go.mod")
+	}
+	return filepath.Join(filepath.Dir(path), "go.mod")
+}
+
+// readFile reads path from the working tree, or from the repository as it
+// existed at --rev when set.
+func readFile(path string, opts options) ([]byte, error) {
+	if opts.rev == "" {
+		return os.ReadFile(path)
+	}
+
+	repo, err := openRepoAt(opts, path)
+	if err != nil {
+		return nil, err
+	}
+	relPath, err := repo.RelPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return repo.ReadFile(opts.rev, relPath)
 }
 
 func marshalWithFormat(v any, format string) ([]byte, error) {
@@ -157,10 +503,21 @@ func outputFormatted(data any, format string) error {
 }
 
 func handleLatest(entries []changelog.ChangelogEntry, format string) error {
-	if len(entries) == 0 {
+	latest := latestEntry(entries)
+	if latest == nil {
 		return fmt.Errorf("no changelog entries found")
 	}
-	return outputFormatted(entries[0], format)
+	return outputFormatted(latest, format)
+}
+
+// latestEntry returns the most recent release that has not been yanked.
+func latestEntry(entries []changelog.ChangelogEntry) *changelog.ChangelogEntry {
+	for i := range entries {
+		if !entries[i].Yanked {
+			return &entries[i]
+		}
+	}
+	return nil
 }
 
 func handleRelease(entries []changelog.ChangelogEntry, release, format string) error {
@@ -172,6 +529,53 @@ func handleRelease(entries []changelog.ChangelogEntry, release, format string) e
 	return fmt.Errorf("version %s not found in changelog", release)
 }
 
+func handleManifest(root string, opts options, repo *git.Repository) error {
+	combined, err := manifestPackages(root, opts, repo)
+	if err != nil {
+		return err
+	}
+	return outputFormatted(combined, opts.format)
+}
+
+// manifestPackages parses the changelog of each release-please package, by
+// path. With --component, only the package with that component is included.
+func manifestPackages(root string, opts options, repo *git.Repository) (map[string]changelog.Package, error) {
+	packages, err := changelog.LoadPackages(root)
+	if err != nil {
+		return nil, err
+	}
+
+	combined := make(map[string]changelog.Package, len(packages))
+	for _, pkg := range packages {
+		if opts.component != "" && pkg.Component != opts.component {
+			continue
+		}
+		entries, err := readChangelog(pkg.ChangelogPath, opts, repo)
+		if err != nil {
+			return nil, fmt.Errorf("package %s: %w", pkg.Path, err)
+		}
+		if opts.contributors {
+			if err := addContributors(repo, entries, opts, pkg.Component); err != nil {
+				return nil, fmt.Errorf("package %s: %w", pkg.Path, err)
+			}
+		}
+		if opts.excludeYanked {
+			entries = changelog.ExcludeYanked(entries)
+		}
+		pkg.SetEntries(entries)
+		pkg.Entries = filterEntries(pkg.Entries, opts.last, opts.sinceDays, time.Now().UTC())
+		if opts.latest {
+			latest := latestEntry(pkg.Entries)
+			pkg.Entries = nil
+			if latest != nil {
+				pkg.Entries = []changelog.ChangelogEntry{*latest}
+			}
+		}
+		combined[pkg.Path] = pkg
+	}
+	return combined, nil
+}
+
 func filterEntries(entries []changelog.ChangelogEntry, last, sinceDays int, now time.Time) []changelog.ChangelogEntry {
 	filtered := entries
 	if last > 0 && last < len(filtered) {
@@ -204,8 +608,43 @@ func validateScopeOptions(o options) error {
 	if o.release != "" && (o.last > 0 || o.sinceDays > 0) {
 		return fmt.Errorf("--release cannot be combined with --last or --since-days")
 	}
+	if o.manifest && (o.release != "" || o.rev != "") {
+		return fmt.Errorf("--manifest cannot be combined with --release or --rev")
+	}
 	if o.last < 0 || o.sinceDays < 0 {
 		return fmt.Errorf("--last and --since-days must be positive integers")
 	}
+	if (o.githubAppID == "") != (o.githubAppKey == "") {
+		return fmt.Errorf("--github-app-id and --github-app-key must be used together")
+	}
+	if o.noDiscovery && o.gitCredential {
+		return fmt.Errorf("--git-credential cannot be combined with --no-token-discovery")
+	}
+	if o.noCache && o.refresh {
+		return fmt.Errorf("--refresh cannot be combined with --no-cache")
+	}
+	if o.workers < 0 {
+		return fmt.Errorf("--workers must be a positive integer")
+	}
+	if o.headingLevel < 0 || o.headingLevel > 5 {
+		return fmt.Errorf("--heading-level must be between 1 and 5, or 0 to auto-detect")
+	}
 	return nil
 }
+
+// logf writes a diagnostic message to stderr.
+func logf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+// logStats reports the API requests made through transport to stderr.
+func logStats(transport *origin.RetryTransport) {
+	stats := transport.Stats()
+	logf("api requests: %d, retries: %d", stats.Requests, stats.Retries)
+	if limit := stats.RateLimit; limit != nil && !limit.Reset.IsZero() {
+		logf("rate limit: %d of %d remaining, resets %s",
+			limit.Remaining, limit.Limit, limit.Reset.Local().Format(time.RFC3339))
+	} else if limit != nil {
+		logf("rate limit: %d of %d remaining", limit.Remaining, limit.Limit)
+	}
+}
@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
 
+	gogit "github.com/go-git/go-git/v5"
+
 	"cl-parse/changelog"
+	"cl-parse/origin"
 )
 
 func TestFilterEntries(t *testing.T) {
@@ -54,3 +59,155 @@ func TestFilterEntries(t *testing.T) {
 		}
 	}
 }
+
+func TestLatestEntrySkipsYanked(t *testing.T) {
+	entries := []changelog.ChangelogEntry{
+		{Version: "1.2.0", Yanked: true},
+		{Version: "1.1.0"},
+		{Version: "1.0.0"},
+	}
+
+	latest := latestEntry(entries)
+	if latest == nil || latest.Version != "1.1.0" {
+		t.Fatalf("latestEntry() = %+v, want 1.1.0", latest)
+	}
+
+	if latest := latestEntry(entries[:1]); latest != nil {
+		t.Errorf("latestEntry() = %+v, want nil when every release is yanked", latest)
+	}
+}
+
+func TestShownDepth(t *testing.T) {
+	now := time.Date(2025, 9, 8, 0, 0, 0, 0, time.UTC)
+	entries := []changelog.ChangelogEntry{
+		{Version: "1.3.0", Date: now},
+		{Version: "1.2.0", Date: now.AddDate(0, 0, -2), Yanked: true},
+		{Version: "1.1.0", Date: now.AddDate(0, 0, -5)},
+		{Version: "1.0.0", Date: now.AddDate(0, 0, -30)},
+	}
+
+	tests := []struct {
+		name string
+		opts options
+		want int
+	}{
+		{"everything", options{}, 4},
+		{"latest", options{latest: true}, 1},
+		{"last two", options{last: 2}, 2},
+		{"last two without yanked", options{last: 2, excludeYanked: true}, 3},
+		{"since", options{sinceDays: 7}, 3},
+		{"release", options{release: "1.1.0"}, 3},
+		{"unknown release", options{release: "9.9.9"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shownDepth(entries, tt.opts, now); got != tt.want {
+				t.Errorf("shownDepth() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadHosts(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if err := os.MkdirAll(filepath.Join(dir, "cl-parse"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	config := `hosts:
+  ghe.example.com:
+    kind: github
+    apiUrl: https://ghe.example.com/api/v3
+  gitlab.example.com:
+    kind: gitlab
+`
+	if err := os.WriteFile(filepath.Join(dir, "cl-parse", "config.yaml"), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(hostsEnv, "gitlab.example.com=gitlab:https://gitlab.example.com/gitlab/api/v4,git.example.com=gitea")
+
+	hosts, err := loadHosts(map[string]string{"git.example.com": "forgejo"})
+	if err != nil {
+		t.Fatalf("loadHosts() error = %v", err)
+	}
+
+	want := map[string]origin.HostConfig{
+		"ghe.example.com":    {Kind: "github", APIURL: "https://ghe.example.com/api/v3"},
+		"gitlab.example.com": {Kind: "gitlab", APIURL: "https://gitlab.example.com/gitlab/api/v4"},
+		"git.example.com":    {Kind: "forgejo"},
+	}
+	if !reflect.DeepEqual(hosts, want) {
+		t.Errorf("loadHosts() = %+v, want %+v", hosts, want)
+	}
+
+	if _, err := loadHosts(map[string]string{"git.example.com": "svn"}); err == nil {
+		t.Error("loadHosts() expected error for unsupported kind")
+	}
+}
+
+func TestManifestPackagesComponent(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"release-please-config.json":    `{"packages": {".": {}, "packages/api": {"component": "api"}}}`,
+		".release-please-manifest.json": `{".": "1.0.0", "packages/api": "2.1.0"}`,
+		"CHANGELOG.md":                  "# Changelog\n\n## 1.0.0 (2025-01-14)\n",
+		"packages/api/CHANGELOG.md":     "# Changelog\n\n## 2.1.0 (2025-01-14)\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	packages, err := manifestPackages(root, options{component: "api"}, nil)
+	if err != nil {
+		t.Fatalf("manifestPackages() error = %v", err)
+	}
+	if len(packages) != 1 {
+		t.Fatalf("manifestPackages() returned %d packages, want 1", len(packages))
+	}
+	pkg, ok := packages["packages/api"]
+	if !ok {
+		t.Fatalf("manifestPackages() = %+v, want packages/api", packages)
+	}
+	if len(pkg.Entries) != 1 || pkg.Entries[0].Version != "2.1.0" {
+		t.Errorf("packages/api entries = %+v, want 2.1.0", pkg.Entries)
+	}
+}
+
+func TestGoModPath(t *testing.T) {
+	root := t.TempDir()
+	if _, err := gogit.PlainInit(root, false); err != nil {
+		t.Fatal(err)
+	}
+	changelogPath := filepath.Join(root, "docs", "CHANGELOG.md")
+
+	tests := []struct {
+		name string
+		path string
+		opts options
+		want string
+	}{
+		{"changelog below the repository root", changelogPath, options{}, filepath.Join(root, "go.mod")},
+		{"explicit go.mod", changelogPath, options{goMod: "mod/go.mod"}, "mod/go.mod"},
+		{"repository from --repo", "CHANGELOG.md", options{repo: root}, filepath.Join(root, "go.mod")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := goModPath(tt.path, tt.opts, nil); got != tt.want {
+				t.Errorf("goModPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	outside := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	if got, want := goModPath(outside, options{}, nil), filepath.Join(filepath.Dir(outside), "go.mod"); got != want {
+		t.Errorf("goModPath() outside a repository = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"cl-parse/changelog"
+)
+
+var coverageCmd = &cobra.Command{
+	Use:   "coverage [flags] [path]",
+	Short: "Report user-facing commits between release tags that are missing from the changelog",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := getOptions(cmd)
+		changelogPath := changelogPathFromArgs(args)
+		types, _ := cmd.Flags().GetStringSlice("types")
+		failUnder, _ := cmd.Flags().GetFloat64("fail-under")
+
+		repo, err := openRepoAt(opts, changelogPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		entries, err := parseChangelog(changelogPath, opts, repo)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		entries = changelog.FilterComponent(entries, opts.component)
+
+		report, err := changelog.Coverage(repo, entries, opts.tagTemplate, opts.component, types)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := outputFormatted(report, opts.format); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if report.Percentage < failUnder {
+			fmt.Fprintf(os.Stderr, "coverage %.1f%% is below the %.1f%% threshold\n",
+				report.Percentage, failUnder)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	coverageCmd.Flags().
+		StringSlice("types", changelog.DefaultCoverageTypes, "conventional commit types that must appear in the changelog")
+	coverageCmd.Flags().
+		Float64("fail-under", 0, "exit non-zero when overall coverage is below this percentage")
+
+	cmd.AddCommand(coverageCmd)
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cl-parse/changelog"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff --base <rev> [flags] [path]",
+	Short: "Report changes to the changelog since a git revision, flagging edits to released sections",
+	Long: "Compare the changelog with its content at a git revision. Exits with status 1 when a\n" +
+		"release that already existed at that revision was changed or removed. Use --format text\n" +
+		"for a human-readable report.",
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := getOptions(cmd)
+		changelogPath := changelogPathFromArgs(args)
+		base, _ := cmd.Flags().GetString("base")
+
+		baseOpts := opts
+		baseOpts.rev = base
+		baseEntries, err := parseChangelog(changelogPath, baseOpts, nil)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		headEntries, err := parseChangelog(changelogPath, opts, nil)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		report := changelog.Diff(
+			changelog.FilterComponent(baseEntries, opts.component),
+			changelog.FilterComponent(headEntries, opts.component),
+		)
+
+		if strings.ToLower(opts.format) == "text" {
+			writeDiffText(os.Stdout, report)
+		} else if err := outputFormatted(report, opts.format); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if !report.OK() {
+			os.Exit(1)
+		}
+	},
+}
+
+// writeDiffText renders a diff report in a human-readable form.
+func writeDiffText(w io.Writer, report changelog.DiffReport) {
+	if len(report.Versions) == 0 {
+		fmt.Fprintln(w, "no changes")
+		return
+	}
+
+	for _, version := range report.Versions {
+		name := version.Version
+		if version.Component != "" {
+			name = version.Component + " " + name
+		}
+
+		fmt.Fprintf(w, "%s (%s)", name, version.Status)
+		if version.Error {
+			fmt.Fprint(w, " ERROR: released section changed")
+		}
+		fmt.Fprintln(w)
+
+		for _, field := range version.Fields {
+			fmt.Fprintf(w, "  ~ %s: %q -> %q\n", field.Field, field.Before, field.After)
+		}
+		for _, change := range version.Added {
+			fmt.Fprintf(w, "  + %s: %s\n", change.Section, change.After.Description)
+		}
+		for _, change := range version.Removed {
+			fmt.Fprintf(w, "  - %s: %s\n", change.Section, change.Before.Description)
+		}
+		for _, change := range version.Modified {
+			fmt.Fprintf(w, "  ~ %s: %s -> %s\n",
+				change.Section, change.Before.Description, change.After.Description)
+		}
+	}
+}
+
+func init() {
+	diffCmd.Flags().String("base", "", "git revision to compare against (tag, branch or commit)")
+	_ = diffCmd.MarkFlagRequired("base")
+
+	cmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"cl-parse/origin"
+)
+
+// hostsEnv holds host mappings in the same "host=kind[:api-url]" form as --host,
+// separated by commas.
+const hostsEnv = "CL_PARSE_HOSTS"
+
+// fileConfig is the layout of the user configuration file.
+type fileConfig struct {
+	Hosts map[string]origin.HostConfig `yaml:"hosts"`
+}
+
+// configPath returns the location of the user configuration file, e.g.
+// ~/.config/cl-parse/config.yaml on Linux.
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cl-parse", "config.yaml"), nil
+}
+
+// loadHosts merges the host mappings from the configuration file, the
+// CL_PARSE_HOSTS environment variable and --host flags, in increasing order
+// of precedence.
+func loadHosts(flags map[string]string) (map[string]origin.HostConfig, error) {
+	hosts := make(map[string]origin.HostConfig)
+
+	if path, err := configPath(); err == nil {
+		content, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		var config fileConfig
+		if err := yaml.Unmarshal(content, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		for host, hostConfig := range config.Hosts {
+			if err := hostConfig.Validate(); err != nil {
+				return nil, fmt.Errorf("%s: host %s: %w", path, host, err)
+			}
+			hosts[host] = hostConfig
+		}
+	}
+
+	if env := os.Getenv(hostsEnv); env != "" {
+		for _, pair := range strings.Split(env, ",") {
+			host, spec, ok := strings.Cut(pair, "=")
+			if !ok {
+				return nil, fmt.Errorf("%s: expected host=kind, got %q", hostsEnv, pair)
+			}
+			if err := addHost(hosts, host, spec); err != nil {
+				return nil, fmt.Errorf("%s: %w", hostsEnv, err)
+			}
+		}
+	}
+
+	for host, spec := range flags {
+		if err := addHost(hosts, host, spec); err != nil {
+			return nil, fmt.Errorf("--host: %w", err)
+		}
+	}
+
+	return hosts, nil
+}
+
+func addHost(hosts map[string]origin.HostConfig, host, spec string) error {
+	hostConfig, err := origin.ParseHostConfig(spec)
+	if err != nil {
+		return fmt.Errorf("host %s: %w", host, err)
+	}
+	hosts[strings.TrimSpace(host)] = hostConfig
+	return nil
+}
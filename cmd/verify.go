@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"cl-parse/changelog"
+)
+
+var verifyTagsCmd = &cobra.Command{
+	Use:   "verify-tags [flags] [path]",
+	Short: "Check that every release has a git tag and every tag has a release",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := getOptions(cmd)
+		changelogPath := changelogPathFromArgs(args)
+		tolerance, _ := cmd.Flags().GetInt("date-tolerance")
+
+		repo, err := openRepoAt(opts, changelogPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		entries, err := parseChangelog(changelogPath, opts, repo)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		entries = changelog.FilterComponent(entries, opts.component)
+
+		tags, err := repo.Tags()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		report := changelog.VerifyTags(entries, tags, opts.tagTemplate, opts.component, tolerance)
+		if err := outputFormatted(report, opts.format); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if !report.OK() {
+			os.Exit(1)
+		}
+	},
+}
+
+var verifyCommitsCmd = &cobra.Command{
+	Use:   "verify-commits [flags] [path]",
+	Short: "Check that each change's commit belongs to the release it is listed under",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := getOptions(cmd)
+		changelogPath := changelogPathFromArgs(args)
+
+		repo, err := openRepoAt(opts, changelogPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		entries, err := parseChangelog(changelogPath, opts, repo)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		entries = changelog.FilterComponent(entries, opts.component)
+
+		report, err := changelog.VerifyCommits(repo, entries, opts.tagTemplate, opts.component)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := outputFormatted(report, opts.format); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if !report.OK() {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	verifyTagsCmd.Flags().
+		Int("date-tolerance", 1, "days a heading date may differ from its tag date")
+
+	cmd.AddCommand(verifyTagsCmd)
+	cmd.AddCommand(verifyCommitsCmd)
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"cl-parse/changelog"
+)
+
+var whichCmd = &cobra.Command{
+	Use:   "which [flags] <sha|#issue|!pr> [path]",
+	Short: "Find the earliest release that shipped a commit, issue or pull request",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := getOptions(cmd)
+		query := args[0]
+		changelogPath := changelogPathFromArgs(args[1:])
+
+		entries, err := parseChangelog(changelogPath, opts, nil)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		entries = changelog.FilterComponent(entries, opts.component)
+
+		result := changelog.FindInChangelog(entries, query)
+		if result == nil {
+			repo, err := openRepoAt(opts, changelogPath)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			result, err = changelog.FindInHistory(
+				repo, entries, opts.tagTemplate, opts.component, query)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		if err := outputFormatted(result, opts.format); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	cmd.AddCommand(whichCmd)
+}
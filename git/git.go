@@ -2,42 +2,128 @@ package git
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
-// IsGitRepo checks if the given path is a git repository
+// Signature identifies who authored or committed a change, and when.
+type Signature struct {
+	Name  string    `json:"name"  yaml:"name"  toml:"name"`
+	Email string    `json:"email" yaml:"email" toml:"email"`
+	Date  time.Time `json:"date"  yaml:"date"  toml:"date"`
+}
+
+// Commit holds the metadata of a single commit.
+type Commit struct {
+	Hash      string    `json:"hash"      yaml:"hash"      toml:"hash"`
+	Author    Signature `json:"author"    yaml:"author"    toml:"author"`
+	Committer Signature `json:"committer" yaml:"committer" toml:"committer"`
+	Parents   int       `json:"parents"   yaml:"parents"   toml:"parents"`
+	Subject   string    `json:"subject"   yaml:"subject"   toml:"subject"`
+	Message   string    `json:"message"   yaml:"message"   toml:"message"`
+	Signed    bool      `json:"signed"    yaml:"signed"    toml:"signed"`
+}
+
+// Body returns the commit message without its subject line.
+// If the commit is a single line, it will return an empty string.
+func (c *Commit) Body() string {
+	parts := strings.Split(c.Message, "\n")[1:]
+	if len(parts) > 0 && parts[0] == "" {
+		parts = parts[1:]
+	}
+	if len(parts) > 0 && parts[len(parts)-1] == "" {
+		parts = parts[:len(parts)-1]
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+// Tag is a git tag resolved to the commit it points at.
+type Tag struct {
+	Name   string    `json:"name"   yaml:"name"   toml:"name"`
+	Commit string    `json:"commit" yaml:"commit" toml:"commit"`
+	Date   time.Time `json:"date"   yaml:"date"   toml:"date"`
+}
+
+// Repository is an open git repository that can be reused across lookups.
+type Repository struct {
+	repo *git.Repository
+	root string
+}
+
+// Open opens the git repository containing path. Parent directories are
+// searched for a .git directory, so any path inside the work tree can be used.
+func Open(path string) (*Repository, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	root := path
+	if worktree, err := repo.Worktree(); err == nil {
+		root = worktree.Filesystem.Root()
+	}
+
+	return &Repository{repo: repo, root: root}, nil
+}
+
+// Root returns the top-level directory of the repository's work tree.
+func (r *Repository) Root() string {
+	return r.root
+}
+
+// IsGitRepo checks if the given path is inside a git repository
 func IsGitRepo(path string) bool {
-	_, err := git.PlainOpen(path)
+	_, err := Open(path)
 	return err == nil
 }
 
 // GetCommmitBodyFromSha retrieves the commit body for a given SHA
 // If the commit is a single line, it will return an empty string.
 func GetCommmitBodyFromSha(path string, sha string) (string, error) {
-	repo, err := git.PlainOpen(path)
+	repo, err := Open(path)
 	if err != nil {
-		return "", fmt.Errorf("failed to open repository: %w", err)
+		return "", err
 	}
+	return repo.CommitBody(sha)
+}
 
-	hash := plumbing.NewHash(sha)
-	commit, err := repo.CommitObject(hash)
+// CommitBody retrieves the commit body for a given SHA
+// If the commit is a single line, it will return an empty string.
+func (r *Repository) CommitBody(sha string) (string, error) {
+	commit, err := r.Commit(sha)
 	if err != nil {
-		return "", fmt.Errorf("failed to get commit object: %w", err)
+		return "", err
 	}
+	return commit.Body(), nil
+}
 
-	// extract the commit body from the commit message
-	parts := strings.Split(commit.Message, "\n")[1:]
-	if len(parts) > 0 && parts[0] == "" {
-		parts = parts[1:]
-	}
-	if len(parts) > 0 && parts[len(parts)-1] == "" {
-		parts = parts[:len(parts)-1]
+// Commit retrieves the metadata of the commit with the given SHA
+func (r *Repository) Commit(sha string) (*Commit, error) {
+	hash := plumbing.NewHash(sha)
+	commit, err := r.repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit object: %w", err)
 	}
 
-	return strings.Join(parts, "\n"), nil
+	return newCommit(commit), nil
+}
+
+func newCommit(commit *object.Commit) *Commit {
+	return &Commit{
+		Hash:      commit.Hash.String(),
+		Author:    Signature{commit.Author.Name, commit.Author.Email, commit.Author.When},
+		Committer: Signature{commit.Committer.Name, commit.Committer.Email, commit.Committer.When},
+		Parents:   commit.NumParents(),
+		Subject:   strings.SplitN(commit.Message, "\n", 2)[0],
+		Message:   commit.Message,
+		Signed:    commit.PGPSignature != "",
+	}
 }
 
 // IsValidSha checks if the given string is in the correct format for a git SHA
@@ -57,15 +143,215 @@ func IsValidSha(sha string) bool {
 
 // GetOriginURL retrieves the origin URL for a git repository
 func GetOriginURL(path string) (string, error) {
-	repo, err := git.PlainOpen(path)
+	repo, err := Open(path)
 	if err != nil {
-		return "", fmt.Errorf("failed to open repository: %w", err)
+		return "", err
 	}
+	return repo.OriginURL()
+}
 
-	remote, err := repo.Remote("origin")
+// OriginURL retrieves the origin URL for the repository
+func (r *Repository) OriginURL() (string, error) {
+	remote, err := r.repo.Remote("origin")
 	if err != nil {
 		return "", fmt.Errorf("failed to get remote: %w", err)
 	}
 
 	return remote.Config().URLs[0], nil
 }
+
+// Tags lists the repository's tags. Annotated tags are dated by their tagger,
+// lightweight tags by the commit they point at.
+func (r *Repository) Tags() ([]Tag, error) {
+	refs, err := r.repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	var tags []Tag
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		tag := Tag{Name: ref.Name().Short()}
+
+		if annotated, err := r.repo.TagObject(ref.Hash()); err == nil {
+			commit, err := annotated.Commit()
+			if err != nil {
+				// tags pointing at trees or blobs have no release commit
+				return nil
+			}
+			tag.Commit = commit.Hash.String()
+			tag.Date = annotated.Tagger.When
+		} else {
+			commit, err := r.repo.CommitObject(ref.Hash())
+			if err != nil {
+				return nil
+			}
+			tag.Commit = commit.Hash.String()
+			tag.Date = commit.Committer.When
+		}
+
+		tags = append(tags, tag)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// ResolveCommit resolves a revision such as a tag, branch or full or
+// abbreviated SHA to the full hash of the commit it points at.
+func (r *Repository) ResolveCommit(rev string) (string, error) {
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve revision %s: %w", rev, err)
+	}
+	return hash.String(), nil
+}
+
+// HasCommit reports whether a commit with the given SHA exists in the repository.
+func (r *Repository) HasCommit(sha string) bool {
+	_, err := r.repo.CommitObject(plumbing.NewHash(sha))
+	return err == nil
+}
+
+// IsAncestor reports whether the commit ancestor is reachable from the commit
+// descendant. A commit is considered its own ancestor.
+func (r *Repository) IsAncestor(ancestor, descendant string) (bool, error) {
+	a, err := r.repo.CommitObject(plumbing.NewHash(ancestor))
+	if err != nil {
+		return false, fmt.Errorf("failed to get commit object: %w", err)
+	}
+	d, err := r.repo.CommitObject(plumbing.NewHash(descendant))
+	if err != nil {
+		return false, fmt.Errorf("failed to get commit object: %w", err)
+	}
+
+	return a.IsAncestor(d)
+}
+
+// CommitsBetween returns the commits reachable from to but not from from,
+// walking history from to, like "git log from..to". An empty from lists every
+// ancestor of to.
+func (r *Repository) CommitsBetween(from, to string) ([]*Commit, error) {
+	toCommit, err := r.repo.CommitObject(plumbing.NewHash(to))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit object: %w", err)
+	}
+
+	excluded := make(map[plumbing.Hash]bool)
+	if from != "" {
+		fromCommit, err := r.repo.CommitObject(plumbing.NewHash(from))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commit object: %w", err)
+		}
+		err = object.NewCommitPreorderIter(fromCommit, nil, nil).ForEach(func(c *object.Commit) error {
+			excluded[c.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk history: %w", err)
+		}
+	}
+
+	var commits []*Commit
+	err = object.NewCommitPreorderIter(toCommit, excluded, nil).ForEach(func(c *object.Commit) error {
+		commits = append(commits, newCommit(c))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk history: %w", err)
+	}
+
+	return commits, nil
+}
+
+// HistoryWalker lists the history of several commits in turn, returning each
+// commit at most once, so walking a series of releases from oldest to newest
+// visits every commit only once.
+type HistoryWalker struct {
+	repo *Repository
+	seen map[plumbing.Hash]bool
+}
+
+// NewHistoryWalker returns a HistoryWalker that hasn't visited any commits.
+func (r *Repository) NewHistoryWalker() *HistoryWalker {
+	return &HistoryWalker{repo: r, seen: make(map[plumbing.Hash]bool)}
+}
+
+// Walk returns the commits reachable from to that no earlier call returned,
+// like "git log ^earlier... to".
+func (w *HistoryWalker) Walk(to string) ([]*Commit, error) {
+	toCommit, err := w.repo.repo.CommitObject(plumbing.NewHash(to))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit object: %w", err)
+	}
+
+	var visited []plumbing.Hash
+	var commits []*Commit
+	err = object.NewCommitPreorderIter(toCommit, w.seen, nil).ForEach(func(c *object.Commit) error {
+		visited = append(visited, c.Hash)
+		commits = append(commits, newCommit(c))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk history: %w", err)
+	}
+	for _, hash := range visited {
+		w.seen[hash] = true
+	}
+
+	return commits, nil
+}
+
+// ReadFile reads the file at path, relative to the repository root, as it
+// existed at the given revision (a tag, branch or commit).
+func (r *Repository) ReadFile(rev, path string) ([]byte, error) {
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %s: %w", rev, err)
+	}
+
+	commit, err := r.repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit object: %w", err)
+	}
+
+	file, err := commit.File(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", path, rev, err)
+	}
+
+	content, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", path, rev, err)
+	}
+	return []byte(content), nil
+}
+
+// RelPath converts a path on disk to a slash-separated path relative to the
+// repository root, as used by ReadFile.
+func (r *Repository) RelPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	root, err := filepath.Abs(r.root)
+	if err != nil {
+		return "", err
+	}
+
+	// resolve symlinks (e.g. macOS /tmp) so both sides compare equal
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(filepath.Dir(abs)); err == nil {
+		abs = filepath.Join(resolved, filepath.Base(abs))
+	}
+
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("%s is outside the repository", path)
+	}
+	return filepath.ToSlash(rel), nil
+}
@@ -3,6 +3,7 @@ package git
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
@@ -70,6 +71,37 @@ func TestIsGitRepo(t *testing.T) {
 	}
 }
 
+func TestOpen(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	subdir := filepath.Join(dir, "docs", "nested")
+	if err := os.MkdirAll(subdir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := Open(subdir)
+	if err != nil {
+		t.Fatalf("Open() from subdirectory failed: %v", err)
+	}
+
+	wantRoot, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotRoot, err := filepath.EvalSymlinks(repo.Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotRoot != wantRoot {
+		t.Errorf("Root() = %q, want %q", gotRoot, wantRoot)
+	}
+
+	if _, err := repo.OriginURL(); err == nil {
+		t.Error("OriginURL() expected error for repository without origin")
+	}
+}
+
 func TestGetCommmitBodyFromSha(t *testing.T) {
 	dir, cleanup := setupTestRepo(t)
 	defer cleanup()
@@ -152,6 +184,302 @@ func TestGetCommmitBodyFromSha(t *testing.T) {
 	}
 }
 
+func TestCommit(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "test.txt"), []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Add("test.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	authored := time.Date(2025, 1, 14, 9, 30, 0, 0, time.UTC)
+	committed := authored.Add(time.Hour)
+	hash, err := w.Commit("feat: add thing\n\nLonger description", &git.CommitOptions{
+		Author:    &object.Signature{Name: "Ada", Email: "ada@example.com", When: authored},
+		Committer: &object.Signature{Name: "Bot", Email: "bot@example.com", When: committed},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := r.Commit(hash.String())
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	if commit.Hash != hash.String() || commit.Subject != "feat: add thing" {
+		t.Errorf("Commit() = %+v, want hash %s and subject 'feat: add thing'", commit, hash)
+	}
+	if commit.Author.Name != "Ada" || commit.Author.Email != "ada@example.com" ||
+		!commit.Author.Date.Equal(authored) {
+		t.Errorf("Commit().Author = %+v", commit.Author)
+	}
+	if commit.Committer.Name != "Bot" || !commit.Committer.Date.Equal(committed) {
+		t.Errorf("Commit().Committer = %+v", commit.Committer)
+	}
+	if commit.Parents != 0 || commit.Signed {
+		t.Errorf("Commit() parents = %d, signed = %v, want 0 and false", commit.Parents, commit.Signed)
+	}
+	if commit.Body() != "Longer description" {
+		t.Errorf("Commit().Body() = %q", commit.Body())
+	}
+}
+
+func TestParseTrailers(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []Trailer
+	}{
+		{
+			name: "trailer block",
+			message: "fix: handle nil\n\nLonger body.\n\n" +
+				"Refs: #12\nReviewed-by: Grace <grace@example.com>\nBREAKING CHANGE: removes the old flag\n",
+			want: []Trailer{
+				{Key: "Refs", Value: "#12"},
+				{Key: "Reviewed-by", Value: "Grace <grace@example.com>"},
+				{Key: "BREAKING CHANGE", Value: "removes the old flag"},
+			},
+		},
+		{
+			name:    "continuation lines are folded",
+			message: "feat: thing\n\nBREAKING CHANGE: the config file\n  moved to a new location",
+			want:    []Trailer{{Key: "BREAKING CHANGE", Value: "the config file moved to a new location"}},
+		},
+		{
+			name:    "prose paragraph is not a trailer block",
+			message: "feat: thing\n\nThis explains the change.\nNote: it is fast.\nMore prose here.",
+			want:    nil,
+		},
+		{
+			name: "mixed block with git generated trailer",
+			message: "feat: thing\n\nSome prose line\nAnother prose line\n" +
+				"Signed-off-by: Ada <ada@example.com>",
+			want: []Trailer{{Key: "Signed-off-by", Value: "Ada <ada@example.com>"}},
+		},
+		{
+			name:    "subject is never a trailer",
+			message: "Refs: #1",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseTrailers(tt.message)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTrailers() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitAuthors(t *testing.T) {
+	date := time.Date(2025, 1, 14, 0, 0, 0, 0, time.UTC)
+	commit := &Commit{
+		Author: Signature{Name: "Ada", Email: "ada@example.com", Date: date},
+		Message: "feat: pair on it\n\n" +
+			"Co-authored-by: Grace <grace@example.com>\n" +
+			"Co-authored-by: Ada L <ADA@example.com>\n" +
+			"Signed-off-by: Ada <ada@example.com>",
+	}
+
+	want := []Signature{
+		{Name: "Ada", Email: "ada@example.com", Date: date},
+		{Name: "Grace", Email: "grace@example.com", Date: date},
+	}
+	if got := commit.Authors(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Authors() = %v, want %v", got, want)
+	}
+}
+
+func TestTags(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	committed := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tagged := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	sig := &object.Signature{Name: "test", Email: "test@example.com", When: committed}
+	hash, err := w.Commit("initial", &git.CommitOptions{Author: sig, AllowEmptyCommits: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.CreateTag("v1.0.0", hash, nil); err != nil {
+		t.Fatal(err)
+	}
+	_, err = repo.CreateTag("v1.0.1", hash, &git.CreateTagOptions{
+		Tagger:  &object.Signature{Name: "test", Email: "test@example.com", When: tagged},
+		Message: "release",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags, err := r.Tags()
+	if err != nil {
+		t.Fatalf("Tags() error = %v", err)
+	}
+
+	got := make(map[string]Tag)
+	for _, tag := range tags {
+		got[tag.Name] = tag
+	}
+	if tag := got["v1.0.0"]; tag.Commit != hash.String() || !tag.Date.Equal(committed) {
+		t.Errorf("lightweight tag = %+v, want commit %s dated %v", tag, hash, committed)
+	}
+	if tag := got["v1.0.1"]; tag.Commit != hash.String() || !tag.Date.Equal(tagged) {
+		t.Errorf("annotated tag = %+v, want commit %s dated %v", tag, hash, tagged)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "docs", "CHANGELOG.md")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	sig := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	for _, content := range []string{"## 1.0.0", "## 1.1.0"} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Add("docs/CHANGELOG.md"); err != nil {
+			t.Fatal(err)
+		}
+		hash, err := w.Commit(content, &git.CommitOptions{Author: sig})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if content == "## 1.0.0" {
+			if _, err := repo.CreateTag("v1.0.0", hash, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := os.WriteFile(path, []byte("## uncommitted"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rel, err := r.RelPath(path)
+	if err != nil || rel != "docs/CHANGELOG.md" {
+		t.Fatalf("RelPath() = %q, %v; want docs/CHANGELOG.md", rel, err)
+	}
+
+	for rev, want := range map[string]string{"v1.0.0": "## 1.0.0", "HEAD": "## 1.1.0"} {
+		got, err := r.ReadFile(rev, rel)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) error = %v", rev, err)
+		}
+		if string(got) != want {
+			t.Errorf("ReadFile(%s) = %q, want %q", rev, got, want)
+		}
+	}
+
+	if _, err := r.ReadFile("v9.9.9", rel); err == nil {
+		t.Error("ReadFile() expected error for unknown revision")
+	}
+	if _, err := r.RelPath(os.TempDir()); err == nil {
+		t.Error("RelPath() expected error for path outside the repository")
+	}
+}
+
+func TestHistoryWalker(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	var hashes []string
+	for _, msg := range []string{"first", "second", "third"} {
+		hash, err := w.Commit(msg, &git.CommitOptions{Author: sig, AllowEmptyCommits: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes = append(hashes, hash.String())
+	}
+
+	r, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	walker := r.NewHistoryWalker()
+
+	tests := []struct {
+		to   string
+		want []string
+	}{
+		{hashes[0], []string{"first"}},
+		{hashes[2], []string{"third", "second"}},
+		{hashes[2], nil},
+	}
+	for _, tt := range tests {
+		commits, err := walker.Walk(tt.to)
+		if err != nil {
+			t.Fatalf("Walk() error = %v", err)
+		}
+		var got []string
+		for _, commit := range commits {
+			got = append(got, commit.Subject)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Walk(%s) = %v, want %v", tt.to[:7], got, tt.want)
+		}
+	}
+}
+
 func TestIsValidSha(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,133 @@
+package git
+
+import (
+	"regexp"
+	"strings"
+)
+
+// trailerPattern matches a "Key: value" trailer line. Conventional commits'
+// "BREAKING CHANGE" is the only key allowed to contain a space.
+var trailerPattern = regexp.MustCompile(`^(BREAKING CHANGE|[A-Za-z0-9][A-Za-z0-9-]*)\s*:\s*(.*)$`)
+
+// gitGeneratedPrefixes are trailers git itself writes. Their presence lets a
+// final paragraph that mixes prose and trailers still count as a trailer block.
+var gitGeneratedPrefixes = []string{"Signed-off-by: ", "(cherry picked from commit "}
+
+// Trailer is a single "Key: value" line from the end of a commit message.
+type Trailer struct {
+	Key   string `json:"key"   yaml:"key"   toml:"key"`
+	Value string `json:"value" yaml:"value" toml:"value"`
+}
+
+// ParseTrailers extracts the trailers from a commit message following the
+// git-interpret-trailers rules: only the last paragraph is considered, it may
+// not be the subject, and it must consist entirely of trailers unless at
+// least a quarter of its lines are trailers and one of them was generated by
+// git. Indented lines continue the value of the preceding trailer.
+func ParseTrailers(message string) []Trailer {
+	lines := strings.Split(strings.TrimRight(message, "\n"), "\n")
+
+	start := -1
+	for i := len(lines) - 1; i > 0; i-- {
+		if strings.TrimSpace(lines[i]) == "" {
+			start = i + 1
+			break
+		}
+	}
+	if start == -1 || start >= len(lines) {
+		return nil
+	}
+
+	var trailers []Trailer
+	trailerLines, otherLines := 0, 0
+	gitGenerated := false
+	lastWasTrailer := false
+
+	for _, line := range lines[start:] {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if lastWasTrailer && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			last := &trailers[len(trailers)-1]
+			last.Value = strings.TrimSpace(last.Value + " " + strings.TrimSpace(line))
+			continue
+		}
+
+		for _, prefix := range gitGeneratedPrefixes {
+			if strings.HasPrefix(line, prefix) {
+				gitGenerated = true
+			}
+		}
+
+		matches := trailerPattern.FindStringSubmatch(line)
+		if matches == nil {
+			otherLines++
+			lastWasTrailer = false
+			continue
+		}
+
+		trailerLines++
+		lastWasTrailer = true
+		trailers = append(trailers, Trailer{Key: matches[1], Value: strings.TrimSpace(matches[2])})
+	}
+
+	if trailerLines == 0 {
+		return nil
+	}
+	if otherLines > 0 && (!gitGenerated || trailerLines*3 < otherLines) {
+		return nil
+	}
+
+	return trailers
+}
+
+// Trailers returns the trailers at the end of the commit message.
+func (c *Commit) Trailers() []Trailer {
+	return ParseTrailers(c.Message)
+}
+
+// Authors returns the commit author followed by any co-authors listed in
+// "Co-authored-by" trailers, de-duplicated by email address. Co-authors share
+// the author's date.
+func (c *Commit) Authors() []Signature {
+	seen := make(map[string]bool)
+	var authors []Signature
+
+	add := func(name, email string) {
+		key := IdentityKey(name, email)
+		if key == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		authors = append(authors, Signature{Name: name, Email: email, Date: c.Author.Date})
+	}
+
+	add(c.Author.Name, c.Author.Email)
+	for _, trailer := range c.Trailers() {
+		if strings.EqualFold(trailer.Key, "Co-authored-by") {
+			add(parseIdent(trailer.Value))
+		}
+	}
+
+	return authors
+}
+
+// IdentityKey returns the key contributors are de-duplicated by: the lowercased
+// email address, or the lowercased name when there is no email.
+func IdentityKey(name, email string) string {
+	if email != "" {
+		return strings.ToLower(email)
+	}
+	return strings.ToLower(name)
+}
+
+// parseIdent splits a "Name <email>" identity into its name and email.
+func parseIdent(ident string) (name, email string) {
+	start := strings.LastIndex(ident, "<")
+	end := strings.LastIndex(ident, ">")
+	if start == -1 || end < start {
+		return strings.TrimSpace(ident), ""
+	}
+	return strings.TrimSpace(ident[:start]), strings.TrimSpace(ident[start+1 : end])
+}
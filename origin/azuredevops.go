@@ -8,20 +8,24 @@ import (
 	"net/http"
 	"regexp"
 	"strings"
+	"time"
 )
 
 // AzureDevOpsProvider implements IssueProvider for Azure DevOps repositories.
 type AzureDevOpsProvider struct {
 	BaseProvider
-	org string // Azure DevOps organization
+	baseURL string // Organization or collection URL, e.g. "https://dev.azure.com/org"
 }
 
 // NewAzureDevOpsProvider creates a new Azure DevOps provider with the given configuration.
 func NewAzureDevOpsProvider(config Config) *AzureDevOpsProvider {
-	org := parseAzureDevOpsURL(config.URL)
+	baseURL := "https://dev.azure.com/" + parseAzureDevOpsURL(config.URL)
+	if config.APIURL != "" {
+		baseURL = strings.TrimSuffix(config.APIURL, "/")
+	}
 	return &AzureDevOpsProvider{
 		BaseProvider: NewBaseProvider(config),
-		org:          org,
+		baseURL:      baseURL,
 	}
 }
 
@@ -32,15 +36,15 @@ func (a *AzureDevOpsProvider) createRequest(issueNumber string) (*http.Request,
 		issueNumber = issueNumber[1:]
 	}
 	url := fmt.Sprintf(
-		"https://dev.azure.com/%s/_apis/wit/workitems/%s?api-version=7.1",
-		a.org,
+		"%s/_apis/wit/workitems/%s?api-version=7.1",
+		a.baseURL,
 		issueNumber,
 	)
 
 	if isPullRequest {
 		url = fmt.Sprintf(
-			"https://dev.azure.com/%s/_apis/git/pullrequests/%s?api-version=7.1",
-			a.org,
+			"%s/_apis/git/pullrequests/%s?api-version=7.1",
+			a.baseURL,
 			issueNumber,
 		)
 	}
@@ -75,18 +79,46 @@ func (a *AzureDevOpsProvider) GetIssue(issueNumber string) (*Issue, error) {
 	}
 	defer resp.Body.Close()
 
+	type azureIdentity struct {
+		DisplayName string `json:"displayName"`
+	}
+
 	var azureResponse struct {
 		ID     int `json:"id"`
 		Fields struct {
-			Title       string `json:"System.Title"`
-			Description string `json:"System.Description"`
+			Title         string         `json:"System.Title"`
+			Description   string         `json:"System.Description"`
+			State         string         `json:"System.State"`
+			Tags          string         `json:"System.Tags"`
+			IterationPath string         `json:"System.IterationPath"`
+			CreatedBy     azureIdentity  `json:"System.CreatedBy"`
+			AssignedTo    *azureIdentity `json:"System.AssignedTo"`
+			CreatedDate   *time.Time     `json:"System.CreatedDate"`
+			ClosedDate    *time.Time     `json:"Microsoft.VSTS.Common.ClosedDate"`
 		} `json:"fields"`
+		Links struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"_links"`
 	}
 
 	var azurePrResponse struct {
-		ID          int    `json:"pullRequestId"`
-		Title       string `json:"title"`
-		Description string `json:"description"`
+		ID            int           `json:"pullRequestId"`
+		Title         string        `json:"title"`
+		Description   string        `json:"description"`
+		Status        string        `json:"status"`
+		CreatedBy     azureIdentity `json:"createdBy"`
+		CreationDate  *time.Time    `json:"creationDate"`
+		ClosedDate    *time.Time    `json:"closedDate"`
+		SourceRefName string        `json:"sourceRefName"`
+		TargetRefName string        `json:"targetRefName"`
+		Labels        []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+		Repository struct {
+			WebURL string `json:"webUrl"`
+		} `json:"repository"`
 	}
 
 	isPullRequest := len(issueNumber) > 0 && issueNumber[0] == '!'
@@ -94,22 +126,58 @@ func (a *AzureDevOpsProvider) GetIssue(issueNumber string) (*Issue, error) {
 		if err := json.NewDecoder(resp.Body).Decode(&azurePrResponse); err != nil {
 			return nil, fmt.Errorf("failed to decode pull request response: %w", err)
 		}
-		return &Issue{
-			Number: "!" + fmt.Sprintf("%d", azurePrResponse.ID),
-			Title:  azurePrResponse.Title,
-			Body:   cleanDescription(azurePrResponse.Description),
-		}, nil
+		issue := &Issue{
+			Number:       "!" + fmt.Sprintf("%d", azurePrResponse.ID),
+			Kind:         ItemPullRequest,
+			Title:        azurePrResponse.Title,
+			Body:         cleanDescription(azurePrResponse.Description),
+			State:        azurePrResponse.Status,
+			Author:       azurePrResponse.CreatedBy.DisplayName,
+			CreatedAt:    azurePrResponse.CreationDate,
+			ClosedAt:     azurePrResponse.ClosedDate,
+			SourceBranch: strings.TrimPrefix(azurePrResponse.SourceRefName, "refs/heads/"),
+			TargetBranch: strings.TrimPrefix(azurePrResponse.TargetRefName, "refs/heads/"),
+		}
+		if azurePrResponse.Repository.WebURL != "" {
+			issue.URL = fmt.Sprintf("%s/pullrequest/%d", azurePrResponse.Repository.WebURL, azurePrResponse.ID)
+		}
+		for _, label := range azurePrResponse.Labels {
+			issue.Labels = append(issue.Labels, label.Name)
+		}
+		// Completed pull requests have been merged; abandoned ones are only closed.
+		if azurePrResponse.Status == "completed" {
+			issue.MergedAt = azurePrResponse.ClosedDate
+		}
+		return issue, nil
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&azureResponse); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return &Issue{
-		Number: "#" + fmt.Sprintf("%d", azureResponse.ID),
-		Title:  azureResponse.Fields.Title,
-		Body:   cleanDescription(azureResponse.Fields.Description),
-	}, nil
+	fields := azureResponse.Fields
+	issue := &Issue{
+		Number:    "#" + fmt.Sprintf("%d", azureResponse.ID),
+		Kind:      ItemWorkItem,
+		Title:     fields.Title,
+		Body:      cleanDescription(fields.Description),
+		State:     fields.State,
+		URL:       azureResponse.Links.HTML.Href,
+		Author:    fields.CreatedBy.DisplayName,
+		Milestone: fields.IterationPath,
+		CreatedAt: fields.CreatedDate,
+		ClosedAt:  fields.ClosedDate,
+	}
+	if fields.AssignedTo != nil {
+		issue.Assignees = []string{fields.AssignedTo.DisplayName}
+	}
+	// Tags are stored as a single "; "-separated string.
+	for _, tag := range strings.Split(fields.Tags, ";") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			issue.Labels = append(issue.Labels, tag)
+		}
+	}
+	return issue, nil
 }
 
 // parseAzureDevOpsURL extracts organization name from an Azure DevOps URL.
@@ -0,0 +1,170 @@
+package origin
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// BitbucketProvider implements IssueProvider for Bitbucket Cloud repositories.
+type BitbucketProvider struct {
+	BaseProvider
+	apiURL    string // Bitbucket API base URL
+	workspace string // Bitbucket workspace
+	repo      string // Bitbucket repository slug
+}
+
+// NewBitbucketProvider creates a new Bitbucket provider with the given configuration.
+func NewBitbucketProvider(config Config) *BitbucketProvider {
+	workspace, repo := parseBitbucketURL(config.URL)
+	apiURL := "https://api.bitbucket.org/2.0"
+	if config.APIURL != "" {
+		apiURL = strings.TrimSuffix(config.APIURL, "/")
+	}
+	return &BitbucketProvider{
+		BaseProvider: NewBaseProvider(config),
+		apiURL:       apiURL,
+		workspace:    workspace,
+		repo:         repo,
+	}
+}
+
+// createRequest creates a Bitbucket API request with appropriate headers.
+// Tokens of the form "username:app-password" use basic auth; any other token
+// is sent as a bearer (repository or workspace access) token.
+func (b *BitbucketProvider) createRequest(issueNumber string) (*http.Request, error) {
+	isPullRequest, number := parseBitbucketToken(issueNumber)
+
+	kind := "issues"
+	if isPullRequest {
+		kind = "pullrequests"
+	}
+	url := fmt.Sprintf("%s/repositories/%s/%s/%s/%s",
+		b.apiURL, b.workspace, b.repo, kind, number)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if strings.Contains(b.config.Token, ":") {
+		encoded := base64.StdEncoding.EncodeToString([]byte(b.config.Token))
+		req.Header.Set("Authorization", "Basic "+encoded)
+	} else if b.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.config.Token)
+	}
+
+	return req, nil
+}
+
+// GetIssue fetches issue or pull request details from Bitbucket.
+func (b *BitbucketProvider) GetIssue(issueNumber string) (*Issue, error) {
+	req, err := b.createRequest(issueNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := b.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, nil
+	}
+	defer resp.Body.Close()
+
+	type bitbucketUser struct {
+		DisplayName string `json:"display_name"`
+	}
+	type bitbucketEndpoint struct {
+		Branch struct {
+			Name string `json:"name"`
+		} `json:"branch"`
+	}
+	var raw struct {
+		ID          int    `json:"id"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Content     struct {
+			Raw string `json:"raw"`
+		} `json:"content"`
+		State     string         `json:"state"`
+		Author    bitbucketUser  `json:"author"`
+		Reporter  bitbucketUser  `json:"reporter"`
+		Assignee  *bitbucketUser `json:"assignee"`
+		CreatedOn *time.Time     `json:"created_on"`
+		Milestone *struct {
+			Name string `json:"name"`
+		} `json:"milestone"`
+		Source      bitbucketEndpoint `json:"source"`
+		Destination bitbucketEndpoint `json:"destination"`
+		Links       struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"links"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if isPullRequest, _ := parseBitbucketToken(issueNumber); isPullRequest {
+		return &Issue{
+			Number:       "!" + fmt.Sprintf("%d", raw.ID),
+			Kind:         ItemPullRequest,
+			Title:        raw.Title,
+			Body:         raw.Description,
+			State:        strings.ToLower(raw.State),
+			URL:          raw.Links.HTML.Href,
+			Author:       raw.Author.DisplayName,
+			CreatedAt:    raw.CreatedOn,
+			SourceBranch: raw.Source.Branch.Name,
+			TargetBranch: raw.Destination.Branch.Name,
+		}, nil
+	}
+
+	issue := &Issue{
+		Number:    "#" + fmt.Sprintf("%d", raw.ID),
+		Kind:      ItemIssue,
+		Title:     raw.Title,
+		Body:      raw.Content.Raw,
+		State:     raw.State,
+		URL:       raw.Links.HTML.Href,
+		Author:    raw.Reporter.DisplayName,
+		CreatedAt: raw.CreatedOn,
+	}
+	if raw.Assignee != nil {
+		issue.Assignees = []string{raw.Assignee.DisplayName}
+	}
+	if raw.Milestone != nil {
+		issue.Milestone = raw.Milestone.Name
+	}
+	return issue, nil
+}
+
+// parseBitbucketToken splits an issue token ("#1") or pull request token
+// ("!1" or "pull-requests/1", from a pull request link) into its kind and number.
+func parseBitbucketToken(token string) (isPullRequest bool, number string) {
+	if strings.HasPrefix(token, "pull-requests/") {
+		return true, strings.TrimPrefix(token, "pull-requests/")
+	}
+	if len(token) > 0 && (token[0] == '#' || token[0] == '!') {
+		return token[0] == '!', token[1:]
+	}
+	return false, token
+}
+
+// parseBitbucketURL extracts workspace and repository slug from a Bitbucket URL.
+func parseBitbucketURL(url string) (workspace, repo string) {
+	_, path := parseRemoteURL(url)
+	parts := strings.Split(path, "/")
+	if len(parts) >= 2 {
+		return parts[0], parts[1]
+	}
+	return "", ""
+}
@@ -0,0 +1,169 @@
+package origin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultCacheTTL is how long a cached item is used without revalidating it.
+const DefaultCacheTTL = 24 * time.Hour
+
+// Cache stores API responses on disk so unchanged items aren't downloaded on
+// every run. Entries are keyed by request URL, which identifies the provider,
+// repository and item, and by token, so responses fetched with one
+// credential are never served to another. Entries younger than TTL are used
+// as-is; older ones are revalidated with their ETag.
+type Cache struct {
+	Dir string
+	TTL time.Duration
+	// Refresh revalidates every entry regardless of its age.
+	Refresh bool
+}
+
+// cacheEntry is a cached response body and its validator.
+type cacheEntry struct {
+	URL      string    `json:"url"`
+	ETag     string    `json:"etag,omitempty"`
+	StoredAt time.Time `json:"storedAt"`
+	Body     []byte    `json:"body"`
+}
+
+// DefaultCacheDir returns the cache directory under the user cache dir, e.g.
+// ~/.cache/cl-parse on Linux.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cl-parse"), nil
+}
+
+// NewCache creates a cache in dir with the given TTL.
+func NewCache(dir string, ttl time.Duration) *Cache {
+	return &Cache{Dir: dir, TTL: ttl}
+}
+
+// Prune removes entries stored more than maxAge ago, or every entry when
+// maxAge is zero, and returns how many were removed.
+func (c *Cache) Prune(maxAge time.Duration) (int, error) {
+	files, err := filepath.Glob(filepath.Join(c.Dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, file := range files {
+		if maxAge > 0 {
+			entry, err := readCacheEntry(file)
+			if err == nil && time.Since(entry.StoredAt) <= maxAge {
+				continue
+			}
+		}
+		if err := os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func (c *Cache) path(url, token string) string {
+	sum := sha256.Sum256([]byte(token + "\n" + url))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// load returns the cached entry for a request, if any.
+func (c *Cache) load(url, token string) (*cacheEntry, bool) {
+	entry, err := readCacheEntry(c.path(url, token))
+	if err != nil || entry.URL != url {
+		return nil, false
+	}
+	return entry, true
+}
+
+// fresh reports whether an entry can be used without revalidation.
+func (c *Cache) fresh(entry *cacheEntry) bool {
+	return !c.Refresh && time.Since(entry.StoredAt) < c.TTL
+}
+
+// store saves an entry, replacing the file atomically. Failures are ignored
+// since the cache is only an optimisation.
+func (c *Cache) store(token string, entry *cacheEntry) {
+	if err := os.MkdirAll(c.Dir, 0o700); err != nil {
+		return
+	}
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(c.Dir, ".entry-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), c.path(entry.URL, token)); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+func readCacheEntry(path string) (*cacheEntry, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// cachedResponse builds a successful response serving a cached body.
+func cachedResponse(req *http.Request, entry *cacheEntry) *http.Response {
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(bytes.NewReader(entry.Body)),
+		Request:    req,
+	}
+}
+
+// storeResponse caches a successful response's body and returns a response
+// that can still be read by the caller.
+func (c *Cache) storeResponse(req *http.Request, resp *http.Response, token string) (*http.Response, error) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	entry := &cacheEntry{
+		URL:      req.URL.String(),
+		ETag:     resp.Header.Get("ETag"),
+		StoredAt: time.Now(),
+		Body:     body,
+	}
+	c.store(token, entry)
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
@@ -0,0 +1,190 @@
+package origin
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// tokenEnvs lists the environment variables holding a token for each public
+// service's host, in order of precedence. They're never sent to other hosts,
+// even ones mapped to the same provider kind.
+var tokenEnvs = map[string][]string{
+	"github.com":        {"GITHUB_TOKEN", "GH_TOKEN"},
+	"gitlab.com":        {"GITLAB_TOKEN"},
+	"dev.azure.com":     {"AZURE_DEVOPS_EXT_PAT"},
+	"ssh.dev.azure.com": {"AZURE_DEVOPS_EXT_PAT"},
+}
+
+// enterpriseTokenEnvs lists the variables the GitHub CLI reads for GitHub
+// Enterprise Server hosts, used for hosts mapped to the github kind.
+var enterpriseTokenEnvs = []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+
+// credentialTimeout bounds how long a git credential helper may take.
+const credentialTimeout = 10 * time.Second
+
+// DiscoverToken looks up a token for the repository at config.URL, trying the
+// service's environment variables (GITHUB_TOKEN or GH_TOKEN for github.com,
+// GITLAB_TOKEN for gitlab.com, AZURE_DEVOPS_EXT_PAT for dev.azure.com, or
+// GH_ENTERPRISE_TOKEN for self-hosted GitHub), then the origin host's entry in
+// ~/.netrc (or $NETRC), then, when config.GitCredential is set, `git
+// credential fill`. It returns an empty string when none has one.
+func DiscoverToken(config Config) string {
+	host, _ := parseRemoteURL(config.URL)
+	if host == "" {
+		return ""
+	}
+
+	kind := knownKind(config)
+	envs, public := tokenEnvs[host]
+	if !public && kind == KindGitHub {
+		envs = enterpriseTokenEnvs
+	}
+	for _, env := range envs {
+		if token := os.Getenv(env); token != "" {
+			return token
+		}
+	}
+	if login, password, ok := netrcCredentials(host); ok {
+		return credentialToken(kind, login, password)
+	}
+	if !config.GitCredential {
+		return ""
+	}
+	if login, password, ok := gitCredentials(host); ok {
+		return credentialToken(kind, login, password)
+	}
+	return ""
+}
+
+// knownKind returns the provider kind of the repository without probing, or
+// an empty string for unknown hosts.
+func knownKind(config Config) string {
+	host, _ := parseRemoteURL(config.URL)
+	if hostConfig, ok := lookupHost(config.Hosts, host); ok {
+		return strings.ToLower(hostConfig.Kind)
+	}
+
+	switch {
+	case strings.Contains(config.URL, "github.com"):
+		return KindGitHub
+	case strings.Contains(config.URL, "dev.azure.com"):
+		return KindAzureDevOps
+	case strings.Contains(config.URL, "gitlab.com"):
+		return KindGitLab
+	case strings.Contains(config.URL, "bitbucket.org"):
+		return KindBitbucket
+	case strings.Contains(config.URL, "codeberg.org"):
+		return KindGitea
+	}
+	return ""
+}
+
+// credentialToken turns a login and password into a token. Bitbucket app
+// passwords need the username as well, so they're returned as "login:password".
+func credentialToken(kind, login, password string) string {
+	if kind == KindBitbucket && login != "" {
+		return login + ":" + password
+	}
+	return password
+}
+
+// netrcCredentials returns the login and password for host from the netrc
+// file, falling back to its default entry.
+func netrcCredentials(host string) (login, password string, ok bool) {
+	path := os.Getenv("NETRC")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", false
+		}
+		path = filepath.Join(home, ".netrc")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", false
+	}
+	return parseNetrc(string(content), host)
+}
+
+// netrcEntry is a machine or default entry in a netrc file.
+type netrcEntry struct {
+	machine  string // Empty for the default entry
+	login    string
+	password string
+}
+
+// parseNetrc finds the credentials for host, with or without its port, in
+// netrc content. The default entry is used when no machine matches.
+func parseNetrc(content, host string) (login, password string, ok bool) {
+	var entries []netrcEntry
+	fields := strings.Fields(content)
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "machine":
+			if i+1 < len(fields) {
+				i++
+				entries = append(entries, netrcEntry{machine: fields[i]})
+			}
+		case "default":
+			entries = append(entries, netrcEntry{})
+		case "login", "password":
+			if len(entries) == 0 || i+1 >= len(fields) {
+				continue
+			}
+			i++
+			if fields[i-1] == "login" {
+				entries[len(entries)-1].login = fields[i]
+			} else {
+				entries[len(entries)-1].password = fields[i]
+			}
+		case "macdef":
+			// Macros can contain anything, so ignore the rest of the file.
+			i = len(fields)
+		}
+	}
+
+	hostname := host
+	if i := strings.LastIndex(host, ":"); i != -1 {
+		hostname = host[:i]
+	}
+	for _, name := range []string{host, hostname, ""} {
+		for _, entry := range entries {
+			if entry.machine == name && entry.password != "" {
+				return entry.login, entry.password, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// gitCredentials asks git's configured credential helpers for the HTTPS
+// credentials of host, without prompting.
+func gitCredentials(host string) (login, password string, ok bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), credentialTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "credential", "fill")
+	cmd.Stdin = strings.NewReader("protocol=https\nhost=" + host + "\n\n")
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GCM_INTERACTIVE=never")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", "", false
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), "=")
+		switch key {
+		case "username":
+			login = value
+		case "password":
+			password = value
+		}
+	}
+	return login, password, password != ""
+}
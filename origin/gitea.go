@@ -0,0 +1,149 @@
+package origin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// GiteaProvider implements IssueProvider for Gitea-compatible repositories,
+// including Forgejo and Codeberg.
+type GiteaProvider struct {
+	BaseProvider
+	apiURL string // Gitea API base URL, e.g. "https://codeberg.org/api/v1"
+	owner  string // Repository owner
+	repo   string // Repository name
+}
+
+// NewGiteaProvider creates a new Gitea provider with the given configuration.
+func NewGiteaProvider(config Config) *GiteaProvider {
+	owner, repo := parseGiteaURL(config.URL)
+	apiURL := defaultAPIURL(KindGitea, instanceURL(config.URL))
+	if config.APIURL != "" {
+		apiURL = strings.TrimSuffix(config.APIURL, "/")
+	}
+	return &GiteaProvider{
+		BaseProvider: NewBaseProvider(config),
+		apiURL:       apiURL,
+		owner:        owner,
+		repo:         repo,
+	}
+}
+
+// createRequest creates a Gitea API request with appropriate headers.
+func (g *GiteaProvider) createRequest(issueNumber string) (*http.Request, error) {
+	kind := "issues"
+	if len(issueNumber) > 0 && issueNumber[0] == '!' {
+		kind = "pulls"
+	}
+	if len(issueNumber) > 0 && (issueNumber[0] == '#' || issueNumber[0] == '!') {
+		issueNumber = issueNumber[1:]
+	}
+	url := fmt.Sprintf("%s/repos/%s/%s/%s/%s",
+		g.apiURL, g.owner, g.repo, kind, issueNumber)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if g.config.Token != "" {
+		req.Header.Set("Authorization", "token "+g.config.Token)
+	}
+
+	return req, nil
+}
+
+// GetIssue fetches issue or pull request details from a Gitea instance.
+func (g *GiteaProvider) GetIssue(issueNumber string) (*Issue, error) {
+	req, err := g.createRequest(issueNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := g.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, nil
+	}
+	defer resp.Body.Close()
+
+	type giteaUser struct {
+		Login string `json:"login"`
+	}
+	type giteaBranch struct {
+		Ref string `json:"ref"`
+	}
+	var raw struct {
+		Number    int         `json:"number"`
+		Title     string      `json:"title"`
+		Body      string      `json:"body"`
+		State     string      `json:"state"`
+		HTMLURL   string      `json:"html_url"`
+		User      giteaUser   `json:"user"`
+		Assignees []giteaUser `json:"assignees"`
+		Labels    []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+		Milestone *struct {
+			Title string `json:"title"`
+		} `json:"milestone"`
+		CreatedAt *time.Time  `json:"created_at"`
+		ClosedAt  *time.Time  `json:"closed_at"`
+		MergedAt  *time.Time  `json:"merged_at"`
+		Head      giteaBranch `json:"head"`
+		Base      giteaBranch `json:"base"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	issue := &Issue{
+		Number:    "#" + fmt.Sprintf("%d", raw.Number),
+		Kind:      ItemIssue,
+		Title:     raw.Title,
+		Body:      raw.Body,
+		State:     raw.State,
+		URL:       raw.HTMLURL,
+		Author:    raw.User.Login,
+		CreatedAt: raw.CreatedAt,
+		ClosedAt:  raw.ClosedAt,
+	}
+	for _, label := range raw.Labels {
+		issue.Labels = append(issue.Labels, label.Name)
+	}
+	for _, assignee := range raw.Assignees {
+		issue.Assignees = append(issue.Assignees, assignee.Login)
+	}
+	if raw.Milestone != nil {
+		issue.Milestone = raw.Milestone.Title
+	}
+
+	if len(issueNumber) > 0 && issueNumber[0] == '!' {
+		issue.Number = "!" + fmt.Sprintf("%d", raw.Number)
+		issue.Kind = ItemPullRequest
+		issue.MergedAt = raw.MergedAt
+		issue.SourceBranch = raw.Head.Ref
+		issue.TargetBranch = raw.Base.Ref
+		if issue.MergedAt != nil {
+			issue.State = "merged"
+		}
+	}
+	return issue, nil
+}
+
+// parseGiteaURL extracts owner and repository name from a Gitea remote URL.
+func parseGiteaURL(url string) (owner, repo string) {
+	_, path := parseRemoteURL(url)
+	parts := strings.Split(path, "/")
+	if len(parts) >= 2 {
+		return parts[len(parts)-2], parts[len(parts)-1]
+	}
+	return "", ""
+}
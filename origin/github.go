@@ -5,23 +5,34 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 )
 
 // GitHubProvider implements IssueProvider for GitHub repositories.
 type GitHubProvider struct {
 	BaseProvider
-	owner string // GitHub repository owner
-	repo  string // GitHub repository name
+	apiURL string // GitHub API base URL
+	owner  string // GitHub repository owner
+	repo   string // GitHub repository name
 }
 
 // NewGitHubProvider creates a new GitHub provider with the given configuration.
 func NewGitHubProvider(config Config) *GitHubProvider {
 	owner, repo := parseGitHubURL(config.URL)
-	return &GitHubProvider{
+	apiURL := "https://api.github.com"
+	if config.APIURL != "" {
+		apiURL = strings.TrimSuffix(config.APIURL, "/")
+	}
+	g := &GitHubProvider{
 		BaseProvider: NewBaseProvider(config),
+		apiURL:       apiURL,
 		owner:        owner,
 		repo:         repo,
 	}
+	if config.GitHubApp != nil {
+		g.authorize = g.appAuthorization
+	}
+	return g
 }
 
 // createRequest creates a GitHub API request with appropriate headers.
@@ -29,8 +40,8 @@ func (g *GitHubProvider) createRequest(issueNumber string) (*http.Request, error
 	if len(issueNumber) > 0 && (issueNumber[0] == '#' || issueNumber[0] == '!') {
 		issueNumber = issueNumber[1:]
 	}
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues/%s",
-		g.owner, g.repo, issueNumber)
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/%s",
+		g.apiURL, g.owner, g.repo, issueNumber)
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -40,13 +51,24 @@ func (g *GitHubProvider) createRequest(issueNumber string) (*http.Request, error
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 	req.Header.Set("User-Agent", "go-changelog")
 
-	if g.config.Token != "" {
+	if g.config.Token != "" && g.config.GitHubApp == nil {
 		req.Header.Set("Authorization", "Bearer "+g.config.Token)
 	}
 
 	return req, nil
 }
 
+// appAuthorization authenticates req with an installation token of the
+// GitHub App, so a token is only requested when the cache can't answer.
+func (g *GitHubProvider) appAuthorization(req *http.Request) error {
+	token, err := g.config.GitHubApp.Token(g.client, g.apiURL, g.owner, g.repo)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
 // GetIssue fetches issue details from GitHub.
 func (g *GitHubProvider) GetIssue(issueNumber string) (*Issue, error) {
 	req, err := g.createRequest(issueNumber)
@@ -63,36 +85,71 @@ func (g *GitHubProvider) GetIssue(issueNumber string) (*Issue, error) {
 	}
 	defer resp.Body.Close()
 
+	type githubUser struct {
+		Login string `json:"login"`
+	}
 	var raw struct {
-		Number int    `json:"number"`
-		Title  string `json:"title"`
-		Body   string `json:"body"`
+		Number    int          `json:"number"`
+		Title     string       `json:"title"`
+		Body      string       `json:"body"`
+		State     string       `json:"state"`
+		HTMLURL   string       `json:"html_url"`
+		User      githubUser   `json:"user"`
+		Assignees []githubUser `json:"assignees"`
+		Labels    []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+		Milestone *struct {
+			Title string `json:"title"`
+		} `json:"milestone"`
+		CreatedAt   *time.Time `json:"created_at"`
+		ClosedAt    *time.Time `json:"closed_at"`
+		PullRequest *struct {
+			MergedAt *time.Time `json:"merged_at"`
+		} `json:"pull_request"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	issue := &Issue{Number: "#" + fmt.Sprintf("%d", raw.Number), Title: raw.Title, Body: raw.Body}
+	issue := &Issue{
+		Number:    "#" + fmt.Sprintf("%d", raw.Number),
+		Kind:      ItemIssue,
+		Title:     raw.Title,
+		Body:      raw.Body,
+		State:     raw.State,
+		URL:       raw.HTMLURL,
+		Author:    raw.User.Login,
+		CreatedAt: raw.CreatedAt,
+		ClosedAt:  raw.ClosedAt,
+	}
+	for _, label := range raw.Labels {
+		issue.Labels = append(issue.Labels, label.Name)
+	}
+	for _, assignee := range raw.Assignees {
+		issue.Assignees = append(issue.Assignees, assignee.Login)
+	}
+	if raw.Milestone != nil {
+		issue.Milestone = raw.Milestone.Title
+	}
+	// The issues API serves pull requests too, marking them with a pull_request object.
+	if raw.PullRequest != nil {
+		issue.Kind = ItemPullRequest
+		issue.MergedAt = raw.PullRequest.MergedAt
+		if issue.MergedAt != nil {
+			issue.State = "merged"
+		}
+	}
 	return issue, nil
 }
 
-// parseGitHubURL extracts owner and repository name from a GitHub URL.
+// parseGitHubURL extracts owner and repository name from a GitHub or GitHub
+// Enterprise URL.
 func parseGitHubURL(url string) (owner, repo string) {
-	url = strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
-
-	if strings.HasPrefix(url, "git@github.com:") {
-		parts := strings.Split(strings.TrimPrefix(url, "git@github.com:"), "/")
-		if len(parts) >= 2 {
-			return parts[0], parts[1]
-		}
-		return "", ""
-	}
-
-	parts := strings.Split(url, "/")
-	for i, part := range parts {
-		if part == "github.com" && i+2 < len(parts) {
-			return parts[i+1], parts[i+2]
-		}
+	_, path := parseRemoteURL(url)
+	parts := strings.Split(path, "/")
+	if len(parts) >= 2 {
+		return parts[0], parts[1]
 	}
 	return "", ""
 }
@@ -0,0 +1,210 @@
+package origin
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// tokenRefreshMargin is how long before expiry an installation token is
+// replaced, so requests in flight don't use an expired token.
+const tokenRefreshMargin = 5 * time.Minute
+
+// GitHubApp authenticates GitHub requests as an installation of a GitHub App.
+// It signs a JWT with the app's private key, exchanges it for an installation
+// access token for the repository and replaces the token before it expires.
+type GitHubApp struct {
+	AppID string // App ID or client ID
+	key   *rsa.PrivateKey
+
+	mu       sync.Mutex
+	tokens   map[string]installationToken // By API URL and repository
+	inflight map[string]*tokenFetch       // Token requests in progress, by the same key
+	now      func() time.Time
+}
+
+// tokenFetch is a token request shared by every caller waiting for it.
+type tokenFetch struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// installationToken is an access token and when it expires.
+type installationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewGitHubApp creates a GitHubApp from its ID and PEM-encoded private key.
+func NewGitHubApp(appID string, keyPEM []byte) (*GitHubApp, error) {
+	if appID == "" {
+		return nil, errors.New("missing GitHub App ID")
+	}
+	key, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub App private key: %w", err)
+	}
+	return &GitHubApp{
+		AppID:    appID,
+		key:      key,
+		tokens:   make(map[string]installationToken),
+		inflight: make(map[string]*tokenFetch),
+	}, nil
+}
+
+// LoadGitHubApp creates a GitHubApp with the private key read from keyPath.
+func LoadGitHubApp(appID, keyPath string) (*GitHubApp, error) {
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+	}
+	return NewGitHubApp(appID, keyPEM)
+}
+
+// parsePrivateKey parses a PKCS #1 key, as downloaded from GitHub, or a
+// PKCS #8 RSA key.
+func parsePrivateKey(keyPEM []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an RSA key")
+	}
+	return key, nil
+}
+
+// Token returns an installation access token for owner/repo, requesting a new
+// one when none is cached or the cached one is about to expire. Concurrent
+// callers for the same repository share a single request, while callers for
+// other repositories aren't blocked by it.
+func (a *GitHubApp) Token(client *http.Client, apiURL, owner, repo string) (string, error) {
+	key := apiURL + "/" + owner + "/" + repo
+
+	a.mu.Lock()
+	if token, ok := a.tokens[key]; ok && a.clock().Add(tokenRefreshMargin).Before(token.ExpiresAt) {
+		a.mu.Unlock()
+		return token.Token, nil
+	}
+	if fetch, ok := a.inflight[key]; ok {
+		a.mu.Unlock()
+		<-fetch.done
+		return fetch.token, fetch.err
+	}
+	fetch := &tokenFetch{done: make(chan struct{})}
+	a.inflight[key] = fetch
+	a.mu.Unlock()
+
+	created, err := a.requestToken(client, apiURL, owner, repo)
+
+	a.mu.Lock()
+	delete(a.inflight, key)
+	if err == nil {
+		a.tokens[key] = created
+	}
+	a.mu.Unlock()
+
+	fetch.token, fetch.err = created.Token, err
+	close(fetch.done)
+	return fetch.token, fetch.err
+}
+
+// requestToken finds the app's installation for owner/repo and creates an
+// access token for it.
+func (a *GitHubApp) requestToken(client *http.Client, apiURL, owner, repo string) (installationToken, error) {
+	jwt, err := a.jwt()
+	if err != nil {
+		return installationToken{}, err
+	}
+
+	var installation struct {
+		ID int64 `json:"id"`
+	}
+	url := fmt.Sprintf("%s/repos/%s/%s/installation", apiURL, owner, repo)
+	if err := a.call(client, "GET", url, jwt, http.StatusOK, &installation); err != nil {
+		return installationToken{}, fmt.Errorf("failed to find GitHub App installation for %s/%s: %w", owner, repo, err)
+	}
+
+	var created installationToken
+	url = fmt.Sprintf("%s/app/installations/%d/access_tokens", apiURL, installation.ID)
+	if err := a.call(client, "POST", url, jwt, http.StatusCreated, &created); err != nil {
+		return installationToken{}, fmt.Errorf("failed to create GitHub App installation token: %w", err)
+	}
+	return created, nil
+}
+
+// call sends an API request authenticated as the app and decodes the response.
+func (a *GitHubApp) call(client *http.Client, method, url, jwt string, want int, v any) error {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "go-changelog")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != want {
+		return errors.New(resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// jwt returns a JWT identifying the app, signed with RS256. It is backdated a
+// minute to allow for clock drift and expires within GitHub's ten minute limit.
+func (a *GitHubApp) jwt() (string, error) {
+	now := a.clock()
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": a.AppID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	encoding := base64.RawURLEncoding
+	unsigned := encoding.EncodeToString(header) + "." + encoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App JWT: %w", err)
+	}
+	return unsigned + "." + encoding.EncodeToString(signature), nil
+}
+
+func (a *GitHubApp) clock() time.Time {
+	if a.now != nil {
+		return a.now()
+	}
+	return time.Now()
+}
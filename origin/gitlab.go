@@ -6,30 +6,42 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
 // GitLabProvider implements IssueProvider for GitLab repositories
 type GitLabProvider struct {
 	BaseProvider
+	apiURL  string // GitLab API base URL
 	project string // URL-encoded project path with namespace (e.g., "group/project")
 }
 
 // NewGitLabProvider creates a new GitLab provider with the given configuration
 func NewGitLabProvider(config Config) *GitLabProvider {
 	project := parseGitLabURL(config.URL)
+	apiURL := "https://gitlab.com/api/v4"
+	if config.APIURL != "" {
+		apiURL = strings.TrimSuffix(config.APIURL, "/")
+	}
 	return &GitLabProvider{
 		BaseProvider: NewBaseProvider(config),
+		apiURL:       apiURL,
 		project:      project,
 	}
 }
 
-// createRequest creates a GitLab API request with appropriate headers
+// createRequest creates a GitLab API request with appropriate headers.
+// Tokens prefixed with "!" are looked up as merge requests.
 func (g *GitLabProvider) createRequest(issueNumber string) (*http.Request, error) {
+	kind := "issues"
+	if len(issueNumber) > 0 && issueNumber[0] == '!' {
+		kind = "merge_requests"
+	}
 	if len(issueNumber) > 0 && (issueNumber[0] == '#' || issueNumber[0] == '!') {
 		issueNumber = issueNumber[1:]
 	}
-	url := fmt.Sprintf("https://gitlab.com/api/v4/projects/%s/issues/%s",
-		url.PathEscape(g.project), issueNumber)
+	url := fmt.Sprintf("%s/projects/%s/%s/%s",
+		g.apiURL, url.PathEscape(g.project), kind, issueNumber)
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -43,13 +55,29 @@ func (g *GitLabProvider) createRequest(issueNumber string) (*http.Request, error
 	return req, nil
 }
 
-// GetIssue fetches issue details from GitLab (currently only issues, not MRs).
+// GetIssue fetches issue or merge request details from GitLab.
 func (g *GitLabProvider) GetIssue(issueToken string) (*Issue, error) {
 	issueNumber := issueToken
+	type GitLabUser struct {
+		Username string `json:"username"`
+	}
 	type GitLabIssue struct {
-		IID         int    `json:"iid"`
-		Title       string `json:"title"`
-		Description string `json:"description"`
+		IID          int          `json:"iid"`
+		Title        string       `json:"title"`
+		Description  string       `json:"description"`
+		State        string       `json:"state"`
+		WebURL       string       `json:"web_url"`
+		Labels       []string     `json:"labels"`
+		Author       GitLabUser   `json:"author"`
+		Assignees    []GitLabUser `json:"assignees"`
+		CreatedAt    *time.Time   `json:"created_at"`
+		ClosedAt     *time.Time   `json:"closed_at"`
+		MergedAt     *time.Time   `json:"merged_at"`
+		SourceBranch string       `json:"source_branch"`
+		TargetBranch string       `json:"target_branch"`
+		Milestone    *struct {
+			Title string `json:"title"`
+		} `json:"milestone"`
 	}
 	req, err := g.createRequest(issueNumber)
 	if err != nil {
@@ -70,30 +98,38 @@ func (g *GitLabProvider) GetIssue(issueToken string) (*Issue, error) {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return &Issue{
-		Number: "#" + fmt.Sprintf("%d", gitlabIssue.IID),
-		Title:  gitlabIssue.Title,
-		Body:   gitlabIssue.Description,
-	}, nil
-}
-
-// parseGitLabURL extracts project path from a GitLab URL
-func parseGitLabURL(url string) string {
-	url = strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
-
-	if strings.HasPrefix(url, "git@gitlab.com:") {
-		parts := strings.SplitN(url, ":", 2)
-		if len(parts) == 2 {
-			return strings.TrimSuffix(parts[1], ".git")
-		}
-		return ""
+	issue := &Issue{
+		Number:    "#" + fmt.Sprintf("%d", gitlabIssue.IID),
+		Kind:      ItemIssue,
+		Title:     gitlabIssue.Title,
+		Body:      gitlabIssue.Description,
+		State:     gitlabIssue.State,
+		URL:       gitlabIssue.WebURL,
+		Labels:    gitlabIssue.Labels,
+		Author:    gitlabIssue.Author.Username,
+		CreatedAt: gitlabIssue.CreatedAt,
+		ClosedAt:  gitlabIssue.ClosedAt,
+	}
+	for _, assignee := range gitlabIssue.Assignees {
+		issue.Assignees = append(issue.Assignees, assignee.Username)
+	}
+	if gitlabIssue.Milestone != nil {
+		issue.Milestone = gitlabIssue.Milestone.Title
 	}
 
-	parts := strings.Split(url, "/")
-	for i, part := range parts {
-		if part == "gitlab.com" && i+1 < len(parts) {
-			return strings.Join(parts[i+1:], "/")
-		}
+	if len(issueToken) > 0 && issueToken[0] == '!' {
+		issue.Number = "!" + fmt.Sprintf("%d", gitlabIssue.IID)
+		issue.Kind = ItemMergeRequest
+		issue.MergedAt = gitlabIssue.MergedAt
+		issue.SourceBranch = gitlabIssue.SourceBranch
+		issue.TargetBranch = gitlabIssue.TargetBranch
 	}
-	return ""
+
+	return issue, nil
+}
+
+// parseGitLabURL extracts project path from a GitLab or self-managed GitLab URL
+func parseGitLabURL(url string) string {
+	_, path := parseRemoteURL(url)
+	return path
 }
@@ -0,0 +1,166 @@
+package origin
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Provider kinds accepted in HostConfig.
+const (
+	KindGitHub      = "github"
+	KindGitLab      = "gitlab"
+	KindAzureDevOps = "azuredevops"
+	KindBitbucket   = "bitbucket"
+	KindGitea       = "gitea"
+	KindForgejo     = "forgejo"
+)
+
+var kinds = []string{KindGitHub, KindGitLab, KindAzureDevOps, KindBitbucket, KindGitea, KindForgejo}
+
+// HostConfig maps a self-hosted instance, such as GitHub Enterprise Server or
+// a self-managed GitLab, to a provider kind. APIURL overrides the API base URL,
+// which otherwise defaults to the kind's standard path on the instance (e.g.
+// "https://host/api/v3" for GitHub). Azure DevOps Server and Bitbucket have no
+// standard path, so they need an APIURL.
+type HostConfig struct {
+	Kind   string `yaml:"kind"`
+	APIURL string `yaml:"apiUrl"`
+}
+
+// ParseHostConfig parses a "kind" or "kind:api-url" host specification, e.g.
+// "github:https://ghe.example.com/api/v3".
+func ParseHostConfig(spec string) (HostConfig, error) {
+	kind, apiURL, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	host := HostConfig{Kind: strings.ToLower(kind), APIURL: apiURL}
+	return host, host.Validate()
+}
+
+// Validate checks that the kind is supported and the API URL is absolute. The
+// API URL may only be omitted for kinds with a standard path on the instance.
+func (h HostConfig) Validate() error {
+	if !isKind(h.Kind) {
+		return fmt.Errorf("unsupported git provider kind %q (want one of %s)",
+			h.Kind, strings.Join(kinds, ", "))
+	}
+	if h.APIURL == "" {
+		if defaultAPIURL(h.Kind, "") == "" {
+			return fmt.Errorf("git provider kind %q needs an API URL (%s:api-url)", h.Kind, strings.ToLower(h.Kind))
+		}
+		return nil
+	}
+	u, err := url.Parse(h.APIURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid API URL %q", h.APIURL)
+	}
+	return nil
+}
+
+func isKind(kind string) bool {
+	for _, k := range kinds {
+		if strings.EqualFold(k, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupHost finds the configuration for host, trying the host with and
+// without its port.
+func lookupHost(hosts map[string]HostConfig, host string) (HostConfig, bool) {
+	if host == "" {
+		return HostConfig{}, false
+	}
+	if config, ok := hosts[host]; ok {
+		return config, true
+	}
+	if i := strings.LastIndex(host, ":"); i != -1 {
+		config, ok := hosts[host[:i]]
+		return config, ok
+	}
+	return HostConfig{}, false
+}
+
+// instanceURL returns the web URL of the instance hosting a remote. SSH
+// remotes are assumed to serve their web interface and API over HTTPS.
+func instanceURL(remote string) string {
+	host, _ := parseRemoteURL(remote)
+	if host == "" {
+		return ""
+	}
+	if strings.HasPrefix(remote, "http://") {
+		return "http://" + host
+	}
+	return "https://" + host
+}
+
+// defaultAPIURL returns the standard API base URL of a self-hosted instance,
+// or an empty string when the provider has no self-hosted API of its own.
+func defaultAPIURL(kind, instance string) string {
+	switch strings.ToLower(kind) {
+	case KindGitHub:
+		return instance + "/api/v3"
+	case KindGitLab:
+		return instance + "/api/v4"
+	case KindGitea, KindForgejo:
+		return instance + "/api/v1"
+	}
+	return ""
+}
+
+// probeClient is the HTTP client used to probe unknown hosts.
+var probeClient = &http.Client{Timeout: 10 * time.Second}
+
+// probed caches the kind detected for each instance URL.
+var probed sync.Map
+
+// probes are the API endpoints that identify each provider kind, in the order
+// they are tried.
+var probes = []struct {
+	kind string
+	path string
+}{
+	{KindGitea, "/api/v1/version"},
+	{KindGitHub, "/api/v3/meta"},
+	{KindGitLab, "/api/v4/version"},
+}
+
+// probeHost detects the provider kind of an instance by querying each kind's
+// version or metadata endpoint, returning an empty string when none responds.
+// GitLab requires authentication for its version endpoint, so an unauthorized
+// JSON response also identifies it.
+func probeHost(instance, token string) string {
+	if kind, ok := probed.Load(instance); ok {
+		return kind.(string)
+	}
+
+	kind := ""
+	for _, probe := range probes {
+		req, err := http.NewRequest("GET", instance+probe.path, nil)
+		if err != nil {
+			break
+		}
+		if probe.kind == KindGitLab && token != "" {
+			req.Header.Set("PRIVATE-TOKEN", token)
+		}
+
+		resp, err := probeClient.Do(req)
+		if err != nil {
+			continue
+		}
+		resp.Body.Close()
+
+		isJSON := strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json")
+		if isJSON && (resp.StatusCode == http.StatusOK ||
+			(probe.kind == KindGitLab && resp.StatusCode == http.StatusUnauthorized)) {
+			kind = probe.kind
+			break
+		}
+	}
+
+	probed.Store(instance, kind)
+	return kind
+}
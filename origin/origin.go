@@ -4,13 +4,34 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
+)
+
+// Kinds of Issue.
+const (
+	ItemIssue        = "issue"
+	ItemPullRequest  = "pullRequest"
+	ItemMergeRequest = "mergeRequest"
+	ItemWorkItem     = "workItem"
 )
 
 // Issue represents a work item or issue from a Git provider.
 type Issue struct {
-	Number string `json:"number"          yaml:"number"          toml:"number"`
-	Title  string `json:"title,omitempty" yaml:"title,omitempty" toml:"title,omitempty"`
-	Body   string `json:"body,omitempty"  yaml:"body,omitempty"  toml:"body,omitempty"`
+	Number       string     `json:"number"                 yaml:"number"                 toml:"number"`
+	Kind         string     `json:"kind,omitempty"         yaml:"kind,omitempty"         toml:"kind,omitempty"`
+	Title        string     `json:"title,omitempty"        yaml:"title,omitempty"        toml:"title,omitempty"`
+	Body         string     `json:"body,omitempty"         yaml:"body,omitempty"         toml:"body,omitempty"`
+	State        string     `json:"state,omitempty"        yaml:"state,omitempty"        toml:"state,omitempty"`
+	URL          string     `json:"url,omitempty"          yaml:"url,omitempty"          toml:"url,omitempty"`
+	Labels       []string   `json:"labels,omitempty"       yaml:"labels,omitempty"       toml:"labels,omitempty"`
+	Author       string     `json:"author,omitempty"       yaml:"author,omitempty"       toml:"author,omitempty"`
+	Assignees    []string   `json:"assignees,omitempty"    yaml:"assignees,omitempty"    toml:"assignees,omitempty"`
+	Milestone    string     `json:"milestone,omitempty"    yaml:"milestone,omitempty"    toml:"milestone,omitempty"`
+	CreatedAt    *time.Time `json:"createdAt,omitempty"    yaml:"createdAt,omitempty"    toml:"createdAt,omitempty"`
+	ClosedAt     *time.Time `json:"closedAt,omitempty"     yaml:"closedAt,omitempty"     toml:"closedAt,omitempty"`
+	MergedAt     *time.Time `json:"mergedAt,omitempty"     yaml:"mergedAt,omitempty"     toml:"mergedAt,omitempty"`
+	SourceBranch string     `json:"sourceBranch,omitempty" yaml:"sourceBranch,omitempty" toml:"sourceBranch,omitempty"`
+	TargetBranch string     `json:"targetBranch,omitempty" yaml:"targetBranch,omitempty" toml:"targetBranch,omitempty"`
 }
 
 // IssueProvider defines the interface for fetching issue details from Git providers.
@@ -20,13 +41,45 @@ type IssueProvider interface {
 
 // Config contains the configuration needed to connect to a Git provider.
 type Config struct {
-	URL   string // Repository URL
-	Token string // Authentication token
+	URL    string                // Repository URL
+	Token  string                // Authentication token
+	APIURL string                // API base URL, when not the provider's public API
+	Hosts  map[string]HostConfig // Self-hosted instances by hostname
+	Probe  bool                  // Query unknown hosts' APIs to detect the provider
+	// DiscoverToken looks up a token from the environment or ~/.netrc when
+	// Token is empty.
+	DiscoverToken bool
+	// GitCredential also asks git credential helpers during token discovery.
+	// Helpers may prompt or be slow, so this is opt-in.
+	GitCredential bool
+	// GitHubApp, when set, authenticates GitHub requests as an app
+	// installation instead of with Token.
+	GitHubApp *GitHubApp
+	// HTTPClient sends API requests. When nil, a client shared by all
+	// providers retries rate-limited and failed requests.
+	HTTPClient *http.Client
+	// Cache, when set, stores fetched items on disk between runs.
+	Cache *Cache
 }
 
+// defaultClient is shared by providers created without an HTTP client, so
+// rate limits seen by one apply to all.
+var defaultClient = &http.Client{Transport: NewRetryTransport(nil)}
+
 // NewIssueProvider creates an appropriate IssueProvider based on the repository URL.
-// Currently supports GitHub and Azure DevOps.
+// Hosts listed in the config take precedence; otherwise GitHub, Azure DevOps,
+// GitLab, Bitbucket Cloud and Codeberg are recognised by hostname, and other
+// hosts are probed when enabled.
 func NewIssueProvider(config Config) (IssueProvider, error) {
+	if config.Token == "" && config.DiscoverToken {
+		config.Token = DiscoverToken(config)
+	}
+
+	host, _ := parseRemoteURL(config.URL)
+	if hostConfig, ok := lookupHost(config.Hosts, host); ok {
+		return newHostedProvider(hostConfig, config)
+	}
+
 	if strings.Contains(config.URL, "github.com") {
 		return NewGitHubProvider(config), nil
 	}
@@ -36,35 +89,155 @@ func NewIssueProvider(config Config) (IssueProvider, error) {
 	if strings.Contains(config.URL, "gitlab.com") {
 		return NewGitLabProvider(config), nil
 	}
+	if strings.Contains(config.URL, "bitbucket.org") {
+		return NewBitbucketProvider(config), nil
+	}
+	if strings.Contains(config.URL, "codeberg.org") {
+		return NewGiteaProvider(config), nil
+	}
+
+	if config.Probe && host != "" {
+		if kind := probeHost(instanceURL(config.URL), config.Token); kind != "" {
+			return newHostedProvider(HostConfig{Kind: kind}, config)
+		}
+	}
 	return nil, fmt.Errorf("unsupported git provider for URL: %s", config.URL)
 }
 
+// newHostedProvider creates the IssueProvider for a self-hosted instance,
+// defaulting the API URL to the instance's standard API path when it has one.
+func newHostedProvider(host HostConfig, config Config) (IssueProvider, error) {
+	if config.APIURL == "" {
+		config.APIURL = host.APIURL
+	}
+	if config.APIURL == "" {
+		config.APIURL = defaultAPIURL(host.Kind, instanceURL(config.URL))
+	}
+	if config.APIURL == "" {
+		// The providers would otherwise fall back to the public service,
+		// sending it the instance's token.
+		return nil, fmt.Errorf("missing API URL for self-hosted %s instance: %s", host.Kind, config.URL)
+	}
+
+	switch strings.ToLower(host.Kind) {
+	case KindGitHub:
+		return NewGitHubProvider(config), nil
+	case KindGitLab:
+		return NewGitLabProvider(config), nil
+	case KindAzureDevOps:
+		return NewAzureDevOpsProvider(config), nil
+	case KindBitbucket:
+		return NewBitbucketProvider(config), nil
+	case KindGitea, KindForgejo:
+		return NewGiteaProvider(config), nil
+	}
+	return nil, fmt.Errorf("unsupported git provider kind: %s", host.Kind)
+}
+
+// parseRemoteURL splits an HTTPS, SSH ("ssh://git@host/path") or scp-style
+// ("git@host:path") remote URL into its host and repository path. The host
+// keeps its port for HTTP(S) URLs only, since SSH ports don't apply to the API.
+func parseRemoteURL(remote string) (host, path string) {
+	remote = strings.TrimSuffix(strings.TrimSuffix(remote, "/"), ".git")
+
+	if scheme := strings.Index(remote, "://"); scheme != -1 {
+		isSSH := strings.HasPrefix(remote, "ssh://") || strings.HasPrefix(remote, "git+ssh://")
+		rest := remote[scheme+3:]
+		host, path, _ = strings.Cut(rest, "/")
+		host = host[strings.LastIndex(host, "@")+1:]
+		if i := strings.LastIndex(host, ":"); isSSH && i != -1 {
+			host = host[:i]
+		}
+		return host, path
+	}
+
+	userHost, path, ok := strings.Cut(remote, ":")
+	if !ok {
+		return "", ""
+	}
+	return userHost[strings.LastIndex(userHost, "@")+1:], strings.TrimPrefix(path, "/")
+}
+
 // BaseProvider implements common functionality for all Git providers.
 type BaseProvider struct {
-	config Config
-	client *http.Client
+	config    Config
+	client    *http.Client
+	authorize func(*http.Request) error // Adds credentials once a request is needed, if set
 }
 
 // NewBaseProvider creates a new BaseProvider with the given configuration.
 func NewBaseProvider(config Config) BaseProvider {
+	client := config.HTTPClient
+	if client == nil {
+		client = defaultClient
+	}
 	return BaseProvider{
 		config: config,
-		client: &http.Client{},
+		client: client,
+	}
+}
+
+// cacheKey identifies the credentials a cached response was fetched with.
+// Installation tokens change hourly, so GitHub App responses are keyed by the
+// app instead.
+func (b *BaseProvider) cacheKey() string {
+	if b.config.GitHubApp != nil {
+		return "github-app:" + b.config.GitHubApp.AppID
 	}
+	return b.config.Token
 }
 
 // doRequest performs an HTTP request and handles common response scenarios.
-// Returns nil response for 404 status and error for other non-200 statuses.
+// Returns nil response for 404 status and error for other non-200 statuses,
+// which the client's transport has already retried where worthwhile. When a
+// cache is configured, fresh entries are served without a request and stale
+// ones are revalidated with If-None-Match. Credentials from authorize are only
+// resolved when a request is sent.
 func (b *BaseProvider) doRequest(req *http.Request) (*http.Response, error) {
+	cache := b.config.Cache
+	var cached *cacheEntry
+	if cache != nil {
+		var ok bool
+		if cached, ok = cache.load(req.URL.String(), b.cacheKey()); ok {
+			if cache.fresh(cached) {
+				return cachedResponse(req, cached), nil
+			}
+			if cached.ETag != "" {
+				req.Header.Set("If-None-Match", cached.ETag)
+			}
+		}
+	}
+
+	if b.authorize != nil {
+		if err := b.authorize(req); err != nil {
+			return nil, err
+		}
+	}
+
 	resp, err := b.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get issue details: %w", err)
 	}
 
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		cached.StoredAt = time.Now()
+		cache.store(b.cacheKey(), cached)
+		return cachedResponse(req, cached), nil
+	}
+	if resp.StatusCode == http.StatusOK && cache != nil {
+		return cache.storeResponse(req, resp, b.cacheKey())
+	}
+
 	if resp.StatusCode == http.StatusNotFound {
 		resp.Body.Close()
 		return nil, nil
 	}
+	if resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden && isRateLimited(resp.Header)) {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to get issue details: rate limit exceeded (%s)", resp.Status)
+	}
 	if resp.StatusCode != http.StatusOK {
 		resp.Body.Close()
 		return nil, fmt.Errorf("failed to get issue details: %s", resp.Status)
@@ -1,10 +1,27 @@
 package origin
 
 import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 type rtFunc func(*http.Request) (*http.Response, error)
@@ -29,6 +46,7 @@ func TestNewIssueProvider(t *testing.T) {
 	tests := []struct {
 		name    string
 		url     string
+		hosts   map[string]HostConfig
 		want    string
 		wantErr bool
 	}{
@@ -47,16 +65,50 @@ func TestNewIssueProvider(t *testing.T) {
 			url:  "https://gitlab.com/owner/repo",
 			want: "*origin.GitLabProvider",
 		},
+		{
+			name: "bitbucket provider",
+			url:  "https://bitbucket.org/owner/repo",
+			want: "*origin.BitbucketProvider",
+		},
+		{
+			name: "codeberg provider",
+			url:  "https://codeberg.org/owner/repo",
+			want: "*origin.GiteaProvider",
+		},
+		{
+			name:  "configured forgejo host",
+			url:   "git@git.example.com:owner/repo.git",
+			hosts: map[string]HostConfig{"git.example.com": {Kind: "forgejo"}},
+			want:  "*origin.GiteaProvider",
+		},
+		{
+			name:  "github enterprise host",
+			url:   "https://ghe.example.com/owner/repo",
+			hosts: map[string]HostConfig{"ghe.example.com": {Kind: "github"}},
+			want:  "*origin.GitHubProvider",
+		},
 		{
 			name:    "unsupported provider",
-			url:     "https://bitbucket.org/owner/repo",
+			url:     "https://git.example.com/owner/repo",
+			wantErr: true,
+		},
+		{
+			name:    "unknown configured kind",
+			url:     "https://git.example.com/owner/repo",
+			hosts:   map[string]HostConfig{"git.example.com": {Kind: "svn"}},
+			wantErr: true,
+		},
+		{
+			name:    "bitbucket host without api url",
+			url:     "https://bitbucket.example.com/workspace/repo",
+			hosts:   map[string]HostConfig{"bitbucket.example.com": {Kind: "bitbucket"}},
 			wantErr: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			config := Config{URL: tt.url}
+			config := Config{URL: tt.url, Hosts: tt.hosts}
 			got, err := NewIssueProvider(config)
 
 			if (err != nil) != tt.wantErr {
@@ -67,6 +119,9 @@ func TestNewIssueProvider(t *testing.T) {
 			if !tt.wantErr && got == nil {
 				t.Error("NewIssueProvider() returned nil provider")
 			}
+			if !tt.wantErr && fmt.Sprintf("%T", got) != tt.want {
+				t.Errorf("NewIssueProvider() = %T, want %s", got, tt.want)
+			}
 		})
 	}
 }
@@ -134,6 +189,41 @@ func TestGitHubProvider_GetIssue(t *testing.T) {
 	}
 }
 
+func TestGitHubProvider_GetPullRequestDetails(t *testing.T) {
+	provider := NewGitHubProvider(Config{URL: "https://github.com/owner/repo"})
+	provider.client = stubClient(`{"number":7,"title":"Add feature","body":"Body","state":"closed",` +
+		`"html_url":"https://github.com/owner/repo/pull/7","user":{"login":"alice"},` +
+		`"assignees":[{"login":"bob"},{"login":"carol"}],"labels":[{"name":"enhancement"}],` +
+		`"milestone":{"title":"v1.0"},"created_at":"2025-01-01T00:00:00Z","closed_at":"2025-01-02T00:00:00Z",` +
+		`"pull_request":{"merged_at":"2025-01-02T00:00:00Z"}}`)
+
+	issue, err := provider.GetIssue("#7")
+	if err != nil {
+		t.Fatalf("GetIssue() error = %v", err)
+	}
+
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	closed := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	want := Issue{
+		Number:    "#7",
+		Kind:      ItemPullRequest,
+		Title:     "Add feature",
+		Body:      "Body",
+		State:     "merged",
+		URL:       "https://github.com/owner/repo/pull/7",
+		Labels:    []string{"enhancement"},
+		Author:    "alice",
+		Assignees: []string{"bob", "carol"},
+		Milestone: "v1.0",
+		CreatedAt: &created,
+		ClosedAt:  &closed,
+		MergedAt:  &closed,
+	}
+	if !reflect.DeepEqual(*issue, want) {
+		t.Errorf("GetIssue() = %+v, want %+v", *issue, want)
+	}
+}
+
 func TestParseGitLabURL(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -208,6 +298,140 @@ func TestGitLabProvider_GetIssue(t *testing.T) {
 	}
 }
 
+func TestGitLabProvider_GetMergeRequest(t *testing.T) {
+	var requested string
+	provider := NewGitLabProvider(Config{URL: "https://gitlab.com/group/repo"})
+	provider.client = &http.Client{
+		Transport: rtFunc(func(r *http.Request) (*http.Response, error) {
+			requested = r.URL.String()
+			return stubClient(`{"iid":42,"title":"Test MR","description":"Test Body","state":"merged",` +
+				`"merged_at":"2025-01-14T10:00:00Z","source_branch":"feature","target_branch":"main",` +
+				`"author":{"username":"alice"}}`).Transport.RoundTrip(r)
+		}),
+	}
+
+	mr, err := provider.GetIssue("!42")
+	if err != nil {
+		t.Fatalf("GetIssue() error = %v", err)
+	}
+
+	if requested != "https://gitlab.com/api/v4/projects/group%2Frepo/merge_requests/42" {
+		t.Errorf("GetIssue() requested %s", requested)
+	}
+	mergedAt := time.Date(2025, 1, 14, 10, 0, 0, 0, time.UTC)
+	want := Issue{
+		Number:       "!42",
+		Kind:         ItemMergeRequest,
+		Title:        "Test MR",
+		Body:         "Test Body",
+		State:        "merged",
+		Author:       "alice",
+		MergedAt:     &mergedAt,
+		SourceBranch: "feature",
+		TargetBranch: "main",
+	}
+	if !reflect.DeepEqual(*mr, want) {
+		t.Errorf("GetIssue() = %+v, want %+v", *mr, want)
+	}
+}
+
+func TestGitLabProvider_GetIssueDetails(t *testing.T) {
+	provider := NewGitLabProvider(Config{URL: "https://gitlab.com/group/repo"})
+	provider.client = stubClient(`{"iid":3,"title":"Bug","description":"Body","state":"opened",` +
+		`"web_url":"https://gitlab.com/group/repo/-/issues/3","labels":["bug","p1"],` +
+		`"author":{"username":"alice"},"assignees":[{"username":"bob"}],"milestone":{"title":"16.0"},` +
+		`"created_at":"2025-01-01T00:00:00Z","closed_at":null}`)
+
+	issue, err := provider.GetIssue("#3")
+	if err != nil {
+		t.Fatalf("GetIssue() error = %v", err)
+	}
+
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	want := Issue{
+		Number:    "#3",
+		Kind:      ItemIssue,
+		Title:     "Bug",
+		Body:      "Body",
+		State:     "opened",
+		URL:       "https://gitlab.com/group/repo/-/issues/3",
+		Labels:    []string{"bug", "p1"},
+		Author:    "alice",
+		Assignees: []string{"bob"},
+		Milestone: "16.0",
+		CreatedAt: &created,
+	}
+	if !reflect.DeepEqual(*issue, want) {
+		t.Errorf("GetIssue() = %+v, want %+v", *issue, want)
+	}
+}
+
+func TestAzureDevOpsProvider_GetIssue(t *testing.T) {
+	provider := NewAzureDevOpsProvider(Config{URL: "https://dev.azure.com/org/project/_git/repo"})
+
+	provider.client = stubClient(`{"id":12,"fields":{"System.Title":"Work item",` +
+		`"System.Description":"<p>Body &amp; more</p>","System.State":"Closed","System.Tags":"ui; regression",` +
+		`"System.IterationPath":"project\\Sprint 1","System.CreatedBy":{"displayName":"Alice"},` +
+		`"System.AssignedTo":{"displayName":"Bob"},"System.CreatedDate":"2025-01-01T00:00:00Z",` +
+		`"Microsoft.VSTS.Common.ClosedDate":"2025-01-03T00:00:00Z"},` +
+		`"_links":{"html":{"href":"https://dev.azure.com/org/project/_workitems/edit/12"}}}`)
+
+	item, err := provider.GetIssue("#12")
+	if err != nil {
+		t.Fatalf("GetIssue() error = %v", err)
+	}
+
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	closed := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+	want := Issue{
+		Number:    "#12",
+		Kind:      ItemWorkItem,
+		Title:     "Work item",
+		Body:      "Body & more",
+		State:     "Closed",
+		URL:       "https://dev.azure.com/org/project/_workitems/edit/12",
+		Labels:    []string{"ui", "regression"},
+		Author:    "Alice",
+		Assignees: []string{"Bob"},
+		Milestone: "project\\Sprint 1",
+		CreatedAt: &created,
+		ClosedAt:  &closed,
+	}
+	if !reflect.DeepEqual(*item, want) {
+		t.Errorf("GetIssue() = %+v, want %+v", *item, want)
+	}
+
+	provider.client = stubClient(`{"pullRequestId":5,"title":"PR","description":"Body","status":"completed",` +
+		`"createdBy":{"displayName":"Alice"},"creationDate":"2025-01-01T00:00:00Z",` +
+		`"closedDate":"2025-01-03T00:00:00Z","sourceRefName":"refs/heads/feature",` +
+		`"targetRefName":"refs/heads/main","labels":[{"name":"release"}],` +
+		`"repository":{"webUrl":"https://dev.azure.com/org/project/_git/repo"}}`)
+
+	pr, err := provider.GetIssue("!5")
+	if err != nil {
+		t.Fatalf("GetIssue() error = %v", err)
+	}
+
+	want = Issue{
+		Number:       "!5",
+		Kind:         ItemPullRequest,
+		Title:        "PR",
+		Body:         "Body",
+		State:        "completed",
+		URL:          "https://dev.azure.com/org/project/_git/repo/pullrequest/5",
+		Labels:       []string{"release"},
+		Author:       "Alice",
+		CreatedAt:    &created,
+		ClosedAt:     &closed,
+		MergedAt:     &closed,
+		SourceBranch: "feature",
+		TargetBranch: "main",
+	}
+	if !reflect.DeepEqual(*pr, want) {
+		t.Errorf("GetIssue() = %+v, want %+v", *pr, want)
+	}
+}
+
 func TestParseAzureDevOpsOrganization(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -255,3 +479,905 @@ func TestParseAzureDevOpsOrganization(t *testing.T) {
 		})
 	}
 }
+
+func TestParseBitbucketURL(t *testing.T) {
+	tests := []struct {
+		url           string
+		wantWorkspace string
+		wantRepo      string
+	}{
+		{
+			url:           "https://bitbucket.org/workspace/repo",
+			wantWorkspace: "workspace",
+			wantRepo:      "repo",
+		},
+		{
+			url:           "https://user@bitbucket.org/workspace/repo.git",
+			wantWorkspace: "workspace",
+			wantRepo:      "repo",
+		},
+		{
+			url:           "git@bitbucket.org:workspace/repo.git",
+			wantWorkspace: "workspace",
+			wantRepo:      "repo",
+		},
+		{
+			url:           "ssh://git@bitbucket.org/workspace/repo.git",
+			wantWorkspace: "workspace",
+			wantRepo:      "repo",
+		},
+		{
+			url:           "https://bitbucket.example.com:8443/workspace/repo",
+			wantWorkspace: "workspace",
+			wantRepo:      "repo",
+		},
+		{
+			url:           "ssh://git@bitbucket.example.com:7999/workspace/repo.git",
+			wantWorkspace: "workspace",
+			wantRepo:      "repo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			workspace, repo := parseBitbucketURL(tt.url)
+			if workspace != tt.wantWorkspace {
+				t.Errorf("parseBitbucketURL() workspace = %v, want %v", workspace, tt.wantWorkspace)
+			}
+			if repo != tt.wantRepo {
+				t.Errorf("parseBitbucketURL() repo = %v, want %v", repo, tt.wantRepo)
+			}
+		})
+	}
+}
+
+func TestBitbucketProvider_GetIssue(t *testing.T) {
+	var requested []string
+	var auth []string
+	provider := &BitbucketProvider{
+		BaseProvider: BaseProvider{
+			config: Config{Token: "user:app-password"},
+			client: &http.Client{
+				Transport: rtFunc(func(r *http.Request) (*http.Response, error) {
+					requested = append(requested, r.URL.Path)
+					auth = append(auth, r.Header.Get("Authorization"))
+					return stubClient(
+						`{"id":3,"title":"Test Item","description":"PR Body","content":{"raw":"Issue Body"}}`,
+					).Transport.RoundTrip(r)
+				}),
+			},
+		},
+		apiURL:    "https://api.bitbucket.org/2.0",
+		workspace: "workspace",
+		repo:      "repo",
+	}
+
+	tests := []struct {
+		token    string
+		wantPath string
+		want     Issue
+	}{
+		{
+			token:    "#3",
+			wantPath: "/2.0/repositories/workspace/repo/issues/3",
+			want:     Issue{Number: "#3", Kind: ItemIssue, Title: "Test Item", Body: "Issue Body"},
+		},
+		{
+			token:    "!3",
+			wantPath: "/2.0/repositories/workspace/repo/pullrequests/3",
+			want:     Issue{Number: "!3", Kind: ItemPullRequest, Title: "Test Item", Body: "PR Body"},
+		},
+		{
+			token:    "pull-requests/3",
+			wantPath: "/2.0/repositories/workspace/repo/pullrequests/3",
+			want:     Issue{Number: "!3", Kind: ItemPullRequest, Title: "Test Item", Body: "PR Body"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			requested = nil
+			issue, err := provider.GetIssue(tt.token)
+			if err != nil {
+				t.Fatalf("GetIssue() error = %v", err)
+			}
+			if !reflect.DeepEqual(*issue, tt.want) {
+				t.Errorf("GetIssue() = %+v, want %+v", issue, tt.want)
+			}
+			if len(requested) != 1 || requested[0] != tt.wantPath {
+				t.Errorf("GetIssue() requested %v, want %v", requested, tt.wantPath)
+			}
+		})
+	}
+
+	if auth[0] != "Basic dXNlcjphcHAtcGFzc3dvcmQ=" {
+		t.Errorf("app password auth header = %q", auth[0])
+	}
+
+	provider.config.Token = "access-token"
+	if _, err := provider.GetIssue("#3"); err != nil {
+		t.Fatalf("GetIssue() error = %v", err)
+	}
+	if got := auth[len(auth)-1]; got != "Bearer access-token" {
+		t.Errorf("access token auth header = %q, want bearer", got)
+	}
+}
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		wantHost string
+		wantPath string
+	}{
+		{url: "https://git.example.com/owner/repo.git", wantHost: "git.example.com", wantPath: "owner/repo"},
+		{url: "https://git.example.com:3000/owner/repo", wantHost: "git.example.com:3000", wantPath: "owner/repo"},
+		{url: "https://user@git.example.com/owner/repo/", wantHost: "git.example.com", wantPath: "owner/repo"},
+		{url: "ssh://git@git.example.com:2222/owner/repo.git", wantHost: "git.example.com", wantPath: "owner/repo"},
+		{url: "git@git.example.com:owner/repo.git", wantHost: "git.example.com", wantPath: "owner/repo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			host, path := parseRemoteURL(tt.url)
+			if host != tt.wantHost || path != tt.wantPath {
+				t.Errorf("parseRemoteURL() = %v, %v, want %v, %v", host, path, tt.wantHost, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestGiteaProvider_GetIssue(t *testing.T) {
+	var requested, auth string
+	provider := NewGiteaProvider(Config{URL: "git@codeberg.org:owner/repo.git", Token: "secret"})
+	provider.client = &http.Client{
+		Transport: rtFunc(func(r *http.Request) (*http.Response, error) {
+			requested = r.URL.String()
+			auth = r.Header.Get("Authorization")
+			return stubClient(`{"number":4,"title":"Test Issue","body":"Test Body"}`).Transport.RoundTrip(r)
+		}),
+	}
+
+	issue, err := provider.GetIssue("!4")
+	if err != nil {
+		t.Fatalf("GetIssue() error = %v", err)
+	}
+
+	if issue.Number != "!4" || issue.Title != "Test Issue" || issue.Body != "Test Body" {
+		t.Errorf("GetIssue() = %+v, want {Number: !4, Title: 'Test Issue', Body: 'Test Body'}", issue)
+	}
+	if requested != "https://codeberg.org/api/v1/repos/owner/repo/pulls/4" {
+		t.Errorf("GetIssue() requested %s", requested)
+	}
+	if auth != "token secret" {
+		t.Errorf("GetIssue() authorization = %q, want token auth", auth)
+	}
+
+	if _, err := provider.GetIssue("#4"); err != nil {
+		t.Fatalf("GetIssue() error = %v", err)
+	}
+	if requested != "https://codeberg.org/api/v1/repos/owner/repo/issues/4" {
+		t.Errorf("GetIssue() requested %s", requested)
+	}
+}
+
+func TestParseHostConfig(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    HostConfig
+		wantErr bool
+	}{
+		{spec: "forgejo", want: HostConfig{Kind: "forgejo"}},
+		{spec: "GitHub", want: HostConfig{Kind: "github"}},
+		{
+			spec: "gitlab:https://git.example.com/gitlab/api/v4",
+			want: HostConfig{Kind: "gitlab", APIURL: "https://git.example.com/gitlab/api/v4"},
+		},
+		{
+			spec: "bitbucket:https://bitbucket.example.com/rest/api/2.0",
+			want: HostConfig{Kind: "bitbucket", APIURL: "https://bitbucket.example.com/rest/api/2.0"},
+		},
+		{spec: "svn", wantErr: true},
+		{spec: "github:not-a-url", wantErr: true},
+		{spec: "azuredevops", wantErr: true},
+		{spec: "bitbucket", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseHostConfig(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHostConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseHostConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelfHostedAPIURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		host HostConfig
+		want string
+	}{
+		{
+			name: "github enterprise default",
+			url:  "git@ghe.example.com:owner/repo.git",
+			host: HostConfig{Kind: "github"},
+			want: "https://ghe.example.com/api/v3/repos/owner/repo/issues/5",
+		},
+		{
+			name: "gitlab custom api url",
+			url:  "https://git.example.com/group/sub/repo",
+			host: HostConfig{Kind: "gitlab", APIURL: "https://git.example.com/gitlab/api/v4/"},
+			want: "https://git.example.com/gitlab/api/v4/projects/group%2Fsub%2Frepo/issues/5",
+		},
+		{
+			name: "azure devops server collection",
+			url:  "https://tfs.example.com/tfs/Collection/Project/_git/repo",
+			host: HostConfig{Kind: "azuredevops", APIURL: "https://tfs.example.com/tfs/Collection"},
+			want: "https://tfs.example.com/tfs/Collection/_apis/wit/workitems/5?api-version=7.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requested string
+			client := &http.Client{
+				Transport: rtFunc(func(r *http.Request) (*http.Response, error) {
+					requested = r.URL.String()
+					return stubClient(`{}`).Transport.RoundTrip(r)
+				}),
+			}
+
+			provider, err := NewIssueProvider(Config{
+				URL:   tt.url,
+				Hosts: map[string]HostConfig{mustHost(t, tt.url): tt.host},
+			})
+			if err != nil {
+				t.Fatalf("NewIssueProvider() error = %v", err)
+			}
+			switch p := provider.(type) {
+			case *GitHubProvider:
+				p.client = client
+			case *GitLabProvider:
+				p.client = client
+			case *AzureDevOpsProvider:
+				p.client = client
+			}
+
+			if _, err := provider.GetIssue("#5"); err != nil {
+				t.Fatalf("GetIssue() error = %v", err)
+			}
+			if requested != tt.want {
+				t.Errorf("GetIssue() requested %s, want %s", requested, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeHost(t *testing.T) {
+	var requested []string
+	original := probeClient
+	t.Cleanup(func() { probeClient = original })
+	probeClient = &http.Client{
+		Transport: rtFunc(func(r *http.Request) (*http.Response, error) {
+			requested = append(requested, r.URL.Path)
+			status := http.StatusNotFound
+			if r.URL.Path == "/api/v4/version" {
+				status = http.StatusUnauthorized
+			}
+			header := make(http.Header)
+			header.Set("Content-Type", "application/json")
+			return &http.Response{
+				StatusCode: status,
+				Body:       io.NopCloser(strings.NewReader(`{"message":"401 Unauthorized"}`)),
+				Header:     header,
+			}, nil
+		}),
+	}
+
+	provider, err := NewIssueProvider(Config{URL: "git@gitlab.example.com:group/repo.git", Probe: true})
+	if err != nil {
+		t.Fatalf("NewIssueProvider() error = %v", err)
+	}
+	if _, ok := provider.(*GitLabProvider); !ok {
+		t.Errorf("NewIssueProvider() = %T, want *origin.GitLabProvider", provider)
+	}
+
+	want := []string{"/api/v1/version", "/api/v3/meta", "/api/v4/version"}
+	if !reflect.DeepEqual(requested, want) {
+		t.Errorf("probed %v, want %v", requested, want)
+	}
+
+	requested = nil
+	if _, err := NewIssueProvider(Config{URL: "https://gitlab.example.com/group/repo", Probe: true}); err != nil {
+		t.Fatalf("NewIssueProvider() error = %v", err)
+	}
+	if len(requested) != 0 {
+		t.Errorf("expected cached probe result, probed %v", requested)
+	}
+}
+
+func mustHost(t *testing.T, url string) string {
+	t.Helper()
+	host, _ := parseRemoteURL(url)
+	if host == "" {
+		t.Fatalf("no host in %s", url)
+	}
+	return host
+}
+
+// stubResponses returns a transport serving the given responses in order,
+// where a nil response stands for a network error.
+func stubResponses(responses ...*http.Response) (http.RoundTripper, *int) {
+	calls := 0
+	return rtFunc(func(r *http.Request) (*http.Response, error) {
+		resp := responses[calls]
+		calls++
+		if resp == nil {
+			return nil, fmt.Errorf("connection reset")
+		}
+		if resp.Body == nil {
+			resp.Body = io.NopCloser(strings.NewReader(""))
+		}
+		return resp, nil
+	}), &calls
+}
+
+func response(status int, headers ...string) *http.Response {
+	header := make(http.Header)
+	for i := 0; i+1 < len(headers); i += 2 {
+		header.Set(headers[i], headers[i+1])
+	}
+	return &http.Response{StatusCode: status, Status: http.StatusText(status), Header: header}
+}
+
+func TestRetryTransport(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	reset := fmt.Sprintf("%d", now.Add(30*time.Second).Unix())
+
+	base, calls := stubResponses(
+		response(http.StatusTooManyRequests, "Retry-After", "2"),
+		response(http.StatusForbidden, "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", reset),
+		nil,
+		response(http.StatusBadGateway),
+		response(http.StatusOK, "RateLimit-Limit", "2000", "RateLimit-Remaining", "1999", "RateLimit-Reset", reset),
+	)
+
+	var sleeps []time.Duration
+	transport := NewRetryTransport(base)
+	transport.Sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	transport.now = func() time.Time { return now }
+
+	client := &http.Client{Transport: transport}
+	resp, err := client.Get("https://gitlab.example.com/api/v4/projects/1/issues/1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Get() status = %d, want 200 after retries", resp.StatusCode)
+	}
+
+	if *calls != 5 {
+		t.Errorf("made %d requests, want 5", *calls)
+	}
+	if len(sleeps) != 4 || sleeps[0] != 2*time.Second || sleeps[1] != 30*time.Second {
+		t.Errorf("sleeps = %v, want Retry-After then rate limit reset first", sleeps)
+	}
+	for i, want := range []time.Duration{4 * time.Second, 8 * time.Second} {
+		if d := sleeps[i+2]; d < want/2 || d > want {
+			t.Errorf("backoff %d = %s, want between %s and %s", i, d, want/2, want)
+		}
+	}
+
+	stats := transport.Stats()
+	if stats.Requests != 5 || stats.Retries != 4 {
+		t.Errorf("stats = %+v, want 5 requests and 4 retries", stats)
+	}
+	if stats.RateLimit == nil || stats.RateLimit.Limit != 2000 || stats.RateLimit.Remaining != 1999 ||
+		!stats.RateLimit.Reset.Equal(now.Add(30*time.Second)) {
+		t.Errorf("rate limit = %+v, want GitLab RateLimit headers", stats.RateLimit)
+	}
+}
+
+func TestRetryTransport_GivesUp(t *testing.T) {
+	base, calls := stubResponses(
+		response(http.StatusServiceUnavailable),
+		response(http.StatusServiceUnavailable),
+		response(http.StatusServiceUnavailable),
+	)
+
+	transport := NewRetryTransport(base)
+	transport.MaxRetries = 2
+	transport.Sleep = func(time.Duration) {}
+
+	provider := NewGitHubProvider(Config{
+		URL:        "https://github.com/owner/repo",
+		HTTPClient: &http.Client{Transport: transport},
+	})
+	if _, err := provider.GetIssue("#1"); err == nil {
+		t.Error("GetIssue() expected error after retries are exhausted")
+	}
+	if *calls != 3 {
+		t.Errorf("made %d requests, want 3", *calls)
+	}
+
+	base, calls = stubResponses(response(http.StatusNotFound), response(http.StatusOK))
+	transport = NewRetryTransport(base)
+	provider = NewGitHubProvider(Config{
+		URL:        "https://github.com/owner/repo",
+		HTTPClient: &http.Client{Transport: transport},
+	})
+	if issue, err := provider.GetIssue("#1"); issue != nil || err != nil || *calls != 1 {
+		t.Errorf("GetIssue() = %v, %v after %d requests; want nil without retrying 404", issue, err, *calls)
+	}
+}
+
+func TestRetryTransport_WaitsForReset(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	reset := fmt.Sprintf("%d", now.Add(10*time.Second).Unix())
+	base, _ := stubResponses(
+		response(http.StatusOK, "X-RateLimit-Limit", "60", "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", reset),
+		response(http.StatusOK),
+		response(http.StatusOK),
+	)
+
+	var sleeps []time.Duration
+	transport := NewRetryTransport(base)
+	transport.Sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	transport.now = func() time.Time { return now }
+	client := &http.Client{Transport: transport}
+
+	for range 2 {
+		resp, err := client.Get("https://api.github.com/repos/owner/repo/issues/1")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if len(sleeps) != 1 || sleeps[0] != 10*time.Second {
+		t.Errorf("sleeps = %v, want one wait until the rate limit resets", sleeps)
+	}
+
+	// The exhausted limit belongs to api.github.com, not other hosts.
+	resp, err := client.Get("https://gitlab.com/api/v4/projects/1/issues/1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if len(sleeps) != 1 {
+		t.Errorf("sleeps = %v, want no wait for a different host", sleeps)
+	}
+}
+
+func TestRetryTransport_Cancelled(t *testing.T) {
+	base, calls := stubResponses(
+		response(http.StatusTooManyRequests, "Retry-After", "60"),
+		response(http.StatusOK),
+	)
+	client := &http.Client{Transport: NewRetryTransport(base)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.github.com/repos/owner/repo/issues/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if _, err := client.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Do() returned after %s, want the retry wait cut short", elapsed)
+	}
+	if *calls != 1 {
+		t.Errorf("made %d requests, want 1", *calls)
+	}
+}
+
+func TestCache(t *testing.T) {
+	var requests []*http.Request
+	body := `{"number": 1, "title": "Cached issue", "html_url": "https://github.com/owner/repo/issues/1"}`
+	client := &http.Client{Transport: rtFunc(func(r *http.Request) (*http.Response, error) {
+		requests = append(requests, r)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			return &http.Response{StatusCode: http.StatusNotModified, Body: io.NopCloser(strings.NewReader("")), Header: make(http.Header)}, nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     http.Header{"Etag": []string{`"v1"`}},
+		}, nil
+	})}
+
+	cache := NewCache(t.TempDir(), time.Hour)
+	newProvider := func(token string) *GitHubProvider {
+		return NewGitHubProvider(Config{
+			URL:        "https://github.com/owner/repo",
+			Token:      token,
+			HTTPClient: client,
+			Cache:      cache,
+		})
+	}
+
+	get := func(provider *GitHubProvider) {
+		t.Helper()
+		issue, err := provider.GetIssue("#1")
+		if err != nil {
+			t.Fatalf("GetIssue() error = %v", err)
+		}
+		if issue == nil || issue.Title != "Cached issue" {
+			t.Fatalf("GetIssue() = %+v, want cached issue", issue)
+		}
+	}
+
+	get(newProvider("token"))
+	get(newProvider("token"))
+	if len(requests) != 1 {
+		t.Fatalf("made %d requests, want fresh entry served from cache", len(requests))
+	}
+
+	get(newProvider("other-token"))
+	if len(requests) != 2 {
+		t.Fatalf("made %d requests, want a miss for a different token", len(requests))
+	}
+
+	cache.Refresh = true
+	get(newProvider("token"))
+	if len(requests) != 3 || requests[2].Header.Get("If-None-Match") != `"v1"` {
+		t.Fatalf("requests = %d, want revalidation with If-None-Match", len(requests))
+	}
+
+	if removed, err := cache.Prune(time.Hour); err != nil || removed != 0 {
+		t.Errorf("Prune(1h) = %d, %v, want nothing removed", removed, err)
+	}
+	if removed, err := cache.Prune(0); err != nil || removed != 2 {
+		t.Errorf("Prune(0) = %d, %v, want both entries removed", removed, err)
+	}
+}
+
+func TestParseNetrc(t *testing.T) {
+	content := `machine github.com login octocat password gh-secret
+machine git.example.com:8443
+	login admin
+	password port-secret
+default login anonymous password default-secret
+macdef init
+	machine evil.example.com password nope
+`
+
+	tests := []struct {
+		host         string
+		wantLogin    string
+		wantPassword string
+	}{
+		{"github.com", "octocat", "gh-secret"},
+		{"git.example.com:8443", "admin", "port-secret"},
+		{"gitlab.com", "anonymous", "default-secret"},
+		{"evil.example.com", "anonymous", "default-secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			login, password, ok := parseNetrc(content, tt.host)
+			if !ok || login != tt.wantLogin || password != tt.wantPassword {
+				t.Errorf("parseNetrc() = %q, %q, %v, want %q, %q",
+					login, password, ok, tt.wantLogin, tt.wantPassword)
+			}
+		})
+	}
+
+	if _, _, ok := parseNetrc("machine github.com login octocat", "github.com"); ok {
+		t.Error("parseNetrc() found an entry without a password")
+	}
+}
+
+func TestDiscoverToken(t *testing.T) {
+	// Isolate git from the user's and system's credential helpers.
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "credential.helper")
+	t.Setenv("GIT_CONFIG_VALUE_0", "!f() { echo username=helper; echo password=helper-secret; }; f")
+
+	netrc := filepath.Join(t.TempDir(), "netrc")
+	content := "machine gitlab.com login me password netrc-secret\n" +
+		"machine bitbucket.org login me password app-password\n"
+	if err := os.WriteFile(netrc, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NETRC", netrc)
+	for _, env := range []string{"GITHUB_TOKEN", "GITHUB_ENTERPRISE_TOKEN", "AZURE_DEVOPS_EXT_PAT"} {
+		t.Setenv(env, "")
+	}
+	t.Setenv("GH_TOKEN", "gh-secret")
+	t.Setenv("GH_ENTERPRISE_TOKEN", "ghe-secret")
+	t.Setenv("GITLAB_TOKEN", "gitlab-secret")
+	t.Setenv("AZURE_DEVOPS_EXT_PAT", "azure-secret")
+
+	tests := []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{"GitHub environment", Config{URL: "https://github.com/owner/repo"}, "gh-secret"},
+		{"Azure DevOps environment", Config{URL: "https://dev.azure.com/org/project/_git/repo"}, "azure-secret"},
+		{"GitLab environment", Config{URL: "git@gitlab.com:group/project.git"}, "gitlab-secret"},
+		{"netrc", Config{URL: "https://bitbucket.org/workspace/repo"}, "me:app-password"},
+		{"git credential", Config{URL: "https://codeberg.org/owner/repo", GitCredential: true}, "helper-secret"},
+		{"git credential not enabled", Config{URL: "https://codeberg.org/owner/repo"}, ""},
+		{
+			"self-hosted GitLab doesn't get gitlab.com's token",
+			Config{
+				URL:           "https://gitlab.example.com/group/project",
+				Hosts:         map[string]HostConfig{"gitlab.example.com": {Kind: KindGitLab}},
+				GitCredential: true,
+			},
+			"helper-secret",
+		},
+		{
+			"GitHub Enterprise uses the enterprise token",
+			Config{
+				URL:   "https://ghe.example.com/owner/repo",
+				Hosts: map[string]HostConfig{"ghe.example.com": {Kind: KindGitHub}},
+			},
+			"ghe-secret",
+		},
+		{"unknown host gets no provider token", Config{URL: "https://git.example.com/owner/repo"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiscoverToken(tt.config); got != tt.want {
+				t.Errorf("DiscoverToken() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Setenv("GITHUB_TOKEN", "github-secret")
+	if got := DiscoverToken(Config{URL: "https://github.com/owner/repo"}); got != "github-secret" {
+		t.Errorf("DiscoverToken() = %q, want GITHUB_TOKEN before GH_TOKEN", got)
+	}
+}
+
+func TestGitHubApp(t *testing.T) {
+	key, keyPEM := testAppKey(t)
+	keyPath := filepath.Join(t.TempDir(), "app.pem")
+	if err := os.WriteFile(keyPath, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	issued, requests := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		auth := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repos/owner/repo/installation":
+			verifyAppJWT(t, auth, &key.PublicKey, now)
+			fmt.Fprint(w, `{"id": 42}`)
+		case r.Method == "POST" && r.URL.Path == "/app/installations/42/access_tokens":
+			verifyAppJWT(t, auth, &key.PublicKey, now)
+			issued++
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"token": "ghs_%d", "expires_at": %q}`,
+				issued, now.Add(time.Hour).Format(time.RFC3339))
+		case r.URL.Path == "/repos/owner/repo/issues/1":
+			if auth != fmt.Sprintf("ghs_%d", issued) {
+				t.Errorf("issue requested with %q, want latest installation token", auth)
+			}
+			fmt.Fprint(w, `{"number": 1, "title": "Fix bug"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	app, err := LoadGitHubApp("12345", keyPath)
+	if err != nil {
+		t.Fatalf("LoadGitHubApp() error = %v", err)
+	}
+	app.now = func() time.Time { return now }
+
+	provider := NewGitHubProvider(Config{
+		URL:        "https://github.com/owner/repo",
+		APIURL:     server.URL,
+		Token:      "ignored",
+		GitHubApp:  app,
+		HTTPClient: server.Client(),
+	})
+
+	for range 2 {
+		if issue, err := provider.GetIssue("#1"); err != nil || issue == nil || issue.Title != "Fix bug" {
+			t.Fatalf("GetIssue() = %+v, %v", issue, err)
+		}
+	}
+	if issued != 1 {
+		t.Errorf("issued %d installation tokens, want 1 reused", issued)
+	}
+
+	now = now.Add(58 * time.Minute)
+	if _, err := provider.GetIssue("#1"); err != nil {
+		t.Fatalf("GetIssue() error = %v", err)
+	}
+	if issued != 2 {
+		t.Errorf("issued %d installation tokens, want a refresh before expiry", issued)
+	}
+
+	// Cached responses are served without resolving an installation token.
+	cache := NewCache(t.TempDir(), time.Hour)
+	newCachedProvider := func(app *GitHubApp) *GitHubProvider {
+		return NewGitHubProvider(Config{
+			URL:        "https://github.com/owner/repo",
+			APIURL:     server.URL,
+			GitHubApp:  app,
+			HTTPClient: server.Client(),
+			Cache:      cache,
+		})
+	}
+	if _, err := newCachedProvider(app).GetIssue("#1"); err != nil {
+		t.Fatalf("GetIssue() error = %v", err)
+	}
+	fresh, err := NewGitHubApp("12345", keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests = 0
+	if issue, err := newCachedProvider(fresh).GetIssue("#1"); err != nil || issue == nil || issue.Title != "Fix bug" {
+		t.Fatalf("GetIssue() = %+v, %v", issue, err)
+	}
+	if requests != 0 {
+		t.Errorf("made %d requests, want the cached issue without an installation token", requests)
+	}
+
+	if _, err := NewGitHubApp("12345", []byte("not a key")); err == nil {
+		t.Error("NewGitHubApp() accepted an invalid private key")
+	}
+}
+
+// verifyAppJWT checks that a GitHub App JWT is signed by key and issued by
+// app 12345 at now. It runs in the server's handler, so it reports failures
+// with t.Errorf rather than stopping the test.
+func verifyAppJWT(t *testing.T, jwt string, key *rsa.PublicKey, now time.Time) {
+	t.Helper()
+
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Errorf("JWT %q doesn't have three parts", jwt)
+		return
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Errorf("JWT signature isn't base64url: %v", err)
+		return
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("JWT signature invalid: %v", err)
+		return
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Errorf("JWT payload isn't base64url: %v", err)
+		return
+	}
+	var claims struct {
+		IssuedAt  int64  `json:"iat"`
+		ExpiresAt int64  `json:"exp"`
+		Issuer    string `json:"iss"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Errorf("JWT payload isn't JSON: %v", err)
+		return
+	}
+	if claims.Issuer != "12345" || claims.IssuedAt > now.Unix() || claims.ExpiresAt > now.Add(10*time.Minute).Unix() {
+		t.Errorf("JWT claims = %+v, want app 12345 valid at %s for under ten minutes", claims, now)
+	}
+}
+
+func TestGitHubApp_RefreshDoesNotBlock(t *testing.T) {
+	_, keyPEM := testAppKey(t)
+	app, err := NewGitHubApp("12345", keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reached := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(reached)
+		<-release
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	app.tokens[server.URL+"/owner/cached"] = installationToken{Token: "ghs_cached", ExpiresAt: time.Now().Add(time.Hour)}
+
+	done := make(chan error)
+	go func() {
+		_, err := app.Token(server.Client(), server.URL, "owner", "slow")
+		done <- err
+	}()
+	<-reached
+
+	token, err := app.Token(server.Client(), server.URL, "owner", "cached")
+	close(release)
+	if err != nil || token != "ghs_cached" {
+		t.Errorf("Token() = %q, %v, want cached token while another refresh is in flight", token, err)
+	}
+	if err := <-done; err == nil {
+		t.Error("Token() expected error for a repository without an installation")
+	}
+}
+
+func TestGitHubApp_SharesConcurrentRequests(t *testing.T) {
+	_, keyPEM := testAppKey(t)
+	app, err := NewGitHubApp("12345", keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.Method+" "+r.URL.Path]++
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/repos/owner/repo/installation":
+			// Keep the request in flight while the other callers arrive.
+			time.Sleep(50 * time.Millisecond)
+			fmt.Fprint(w, `{"id": 42}`)
+		case "/app/installations/42/access_tokens":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"token": "ghs_shared", "expires_at": %q}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	const callers = 8
+	tokens := make([]string, callers)
+	errs := make([]error, callers)
+	var wg sync.WaitGroup
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tokens[i], errs[i] = app.Token(server.Client(), server.URL, "owner", "repo")
+		}()
+	}
+	wg.Wait()
+
+	for i := range callers {
+		if errs[i] != nil || tokens[i] != "ghs_shared" {
+			t.Errorf("Token() = %q, %v, want shared token", tokens[i], errs[i])
+		}
+	}
+	want := map[string]int{
+		"GET /repos/owner/repo/installation":       1,
+		"POST /app/installations/42/access_tokens": 1,
+	}
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests = %v, want one installation lookup and one token", requests)
+	}
+}
+
+// testAppKey generates a GitHub App private key and returns it PEM-encoded as
+// downloaded from GitHub.
+func testAppKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
@@ -0,0 +1,318 @@
+package origin
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults for RetryTransport.
+const (
+	DefaultMaxRetries = 4
+	DefaultBaseDelay  = time.Second
+	DefaultMaxDelay   = time.Minute
+)
+
+// RateLimit is the most recent rate limit reported by a provider.
+type RateLimit struct {
+	Limit     int       `json:"limit"     yaml:"limit"     toml:"limit"`
+	Remaining int       `json:"remaining" yaml:"remaining" toml:"remaining"`
+	Reset     time.Time `json:"reset"     yaml:"reset"     toml:"reset"`
+}
+
+// TransportStats counts the requests made through a RetryTransport.
+type TransportStats struct {
+	Requests  int        `json:"requests"            yaml:"requests"            toml:"requests"`
+	Retries   int        `json:"retries"             yaml:"retries"             toml:"retries"`
+	RateLimit *RateLimit `json:"rateLimit,omitempty" yaml:"rateLimit,omitempty" toml:"rateLimit,omitempty"`
+}
+
+// RetryTransport is an http.RoundTripper that retries rate-limited requests,
+// transient server errors and network errors. It waits for the duration given
+// by Retry-After, or until the rate limit resets when GitHub's
+// X-RateLimit-* or GitLab's RateLimit-* headers report no remaining requests,
+// and otherwise backs off exponentially with jitter. Rate limits are tracked
+// per host, so one exhausted API doesn't delay requests to others. Waits are
+// capped at MaxDelay and end early when the request's context is cancelled.
+type RetryTransport struct {
+	Base       http.RoundTripper // Defaults to http.DefaultTransport
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	// Sleep waits between attempts. Defaults to a timer that stops early when
+	// the request's context is cancelled.
+	Sleep func(time.Duration)
+	// Logf, when set, is called for every retry.
+	Logf func(format string, args ...any)
+
+	mu     sync.Mutex
+	stats  TransportStats
+	limits map[string]RateLimit // Latest rate limit by host
+	now    func() time.Time
+}
+
+// NewRetryTransport creates a RetryTransport wrapping base with the default
+// retry policy. A nil base uses http.DefaultTransport.
+func NewRetryTransport(base http.RoundTripper) *RetryTransport {
+	return &RetryTransport{
+		Base:       base,
+		MaxRetries: DefaultMaxRetries,
+		BaseDelay:  DefaultBaseDelay,
+		MaxDelay:   DefaultMaxDelay,
+	}
+}
+
+// Stats returns the requests, retries and latest rate limit seen so far, from
+// whichever host responded last.
+func (t *RetryTransport) Stats() TransportStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := t.stats
+	if stats.RateLimit != nil {
+		limit := *stats.RateLimit
+		stats.RateLimit = &limit
+	}
+	return stats
+}
+
+// RoundTrip sends the request, retrying it as described on RetryTransport.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if wait := t.untilReset(req.URL.Host); wait > 0 {
+		t.logf("rate limit exhausted, waiting %s for %s", wait, req.URL)
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+
+	// Requests with a body can only be retried if the body can be recreated.
+	replayable := req.Body == nil || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		t.mu.Lock()
+		t.stats.Requests++
+		t.mu.Unlock()
+
+		resp, err := t.base().RoundTrip(attemptReq)
+		if err == nil {
+			t.recordRateLimit(req.URL.Host, resp.Header)
+		}
+
+		retry, wait := t.shouldRetry(resp, err, attempt)
+		if !retry || !replayable || attempt >= t.MaxRetries || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		if err != nil {
+			t.logf("request to %s failed (%v), retrying in %s", req.URL, err, wait)
+		} else {
+			t.logf("request to %s returned %s, retrying in %s", req.URL, resp.Status, wait)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		t.mu.Lock()
+		t.stats.Retries++
+		t.mu.Unlock()
+
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// shouldRetry decides whether a response or error is worth retrying and how
+// long to wait first.
+func (t *RetryTransport) shouldRetry(resp *http.Response, err error, attempt int) (bool, time.Duration) {
+	if err != nil {
+		return true, t.backoff(attempt)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+	case resp.StatusCode == http.StatusForbidden && isRateLimited(resp.Header):
+		// GitHub reports secondary rate limits as 403 Forbidden.
+	case resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented:
+	default:
+		return false, 0
+	}
+
+	if wait, ok := retryAfter(resp.Header, t.clock()); ok {
+		return true, min(wait, t.maxDelay())
+	}
+	if remaining(resp.Header) == 0 {
+		if reset, ok := resetTime(resp.Header, t.clock()); ok {
+			return true, min(max(reset.Sub(t.clock()), 0), t.maxDelay())
+		}
+	}
+	return true, t.backoff(attempt)
+}
+
+// backoff returns an exponentially growing delay with jitter, between half and
+// all of BaseDelay*2^attempt.
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	base := t.BaseDelay
+	if base <= 0 {
+		base = DefaultBaseDelay
+	}
+	delay := min(base<<attempt, t.maxDelay())
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// untilReset returns how long to wait before sending a request to host when
+// its last response reported the rate limit as exhausted.
+func (t *RetryTransport) untilReset(host string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	limit, ok := t.limits[host]
+	if !ok || limit.Remaining > 0 || limit.Reset.IsZero() {
+		return 0
+	}
+	return min(max(limit.Reset.Sub(t.clock()), 0), t.maxDelay())
+}
+
+func (t *RetryTransport) recordRateLimit(host string, header http.Header) {
+	limit, ok := parseRateLimit(header, t.clock())
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.limits == nil {
+		t.limits = make(map[string]RateLimit)
+	}
+	t.limits[host] = limit
+	t.stats.RateLimit = &limit
+}
+
+func (t *RetryTransport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+	return t.Base
+}
+
+func (t *RetryTransport) maxDelay() time.Duration {
+	if t.MaxDelay <= 0 {
+		return DefaultMaxDelay
+	}
+	return t.MaxDelay
+}
+
+// sleep waits for d, returning the context's error if it is cancelled first.
+func (t *RetryTransport) sleep(ctx context.Context, d time.Duration) error {
+	if t.Sleep != nil {
+		t.Sleep(d)
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (t *RetryTransport) clock() time.Time {
+	if t.now != nil {
+		return t.now()
+	}
+	return time.Now()
+}
+
+func (t *RetryTransport) logf(format string, args ...any) {
+	if t.Logf != nil {
+		t.Logf(format, args...)
+	}
+}
+
+// rateLimitHeaders are the header prefixes used by GitHub ("X-RateLimit-")
+// and GitLab ("RateLimit-").
+var rateLimitHeaders = []string{"X-RateLimit-", "RateLimit-"}
+
+// parseRateLimit reads the rate limit from a response's headers.
+func parseRateLimit(header http.Header, now time.Time) (RateLimit, bool) {
+	for _, prefix := range rateLimitHeaders {
+		value := header.Get(prefix + "Remaining")
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+
+		limit := RateLimit{Remaining: n}
+		limit.Limit, _ = strconv.Atoi(header.Get(prefix + "Limit"))
+		limit.Reset, _ = resetTime(header, now)
+		return limit, true
+	}
+	return RateLimit{}, false
+}
+
+// remaining returns the number of requests left, or -1 when not reported.
+func remaining(header http.Header) int {
+	for _, prefix := range rateLimitHeaders {
+		if n, err := strconv.Atoi(header.Get(prefix + "Remaining")); err == nil {
+			return n
+		}
+	}
+	return -1
+}
+
+// isRateLimited reports whether a 403 response was caused by a rate limit.
+func isRateLimited(header http.Header) bool {
+	return header.Get("Retry-After") != "" || remaining(header) == 0
+}
+
+// resetTime reads when the rate limit resets. Both providers send a Unix
+// timestamp; small values are treated as a number of seconds from now, as in
+// the IETF RateLimit header draft.
+func resetTime(header http.Header, now time.Time) (time.Time, bool) {
+	for _, prefix := range rateLimitHeaders {
+		n, err := strconv.ParseInt(header.Get(prefix+"Reset"), 10, 64)
+		if err != nil {
+			continue
+		}
+		if n < 1_000_000_000 {
+			return now.Add(time.Duration(n) * time.Second), true
+		}
+		return time.Unix(n, 0), true
+	}
+	return time.Time{}, false
+}
+
+// retryAfter reads the Retry-After header, given either in seconds or as an
+// HTTP date.
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}